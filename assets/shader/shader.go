@@ -1,19 +1,317 @@
 package shader
 
 import (
-	_ "embed"
+	"embed"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/rs/zerolog/log"
 )
 
-//go:embed crt_shader.kage
-var shaderCode []byte
+//go:embed *.kage
+var builtinSources embed.FS
 
-func NewShader() *ebiten.Shader {
-	s, err := ebiten.NewShader(shaderCode)
+type effect struct {
+	file     string
+	uniforms map[string]any
+}
+
+// effects are the built-in ones. Files with the same names loaded from disk take precedence.
+var effects = map[string]effect{
+	"crt":          {file: "crt_shader.kage"},
+	"scanlines":    {file: "scanlines_shader.kage"},
+	"bloom":        {file: "bloom_shader.kage"},
+	"protanopia":   {file: "colourblind_shader.kage", uniforms: map[string]any{"Deficiency": float32(0)}},
+	"deuteranopia": {file: "colourblind_shader.kage", uniforms: map[string]any{"Deficiency": float32(1)}},
+	"tritanopia":   {file: "colourblind_shader.kage", uniforms: map[string]any{"Deficiency": float32(2)}},
+}
+
+// PresetNames are listed in the order they're cycled through at runtime.
+var PresetNames = []string{"crt", "arcade", "scanlines", "soft", "off"}
+
+var presets = map[string][]string{
+	"crt":       {"crt"},
+	"arcade":    {"bloom", "crt"},
+	"scanlines": {"scanlines"},
+	"soft":      {"bloom"},
+	"off":       nil,
+}
+
+var defaultUniforms = map[string]any{
+	"Curvature":      float32(1),
+	"Vignette":       float32(1),
+	"Scanlines":      float32(1),
+	"Bloom":          float32(0.6),
+	"BloomThreshold": float32(0.6),
+	"Correction":     float32(1),
+}
+
+type pass struct {
+	name   string
+	shader *ebiten.Shader
+	// uniforms are the parameters of the pipeline merged with the ones specific to the effect
+	uniforms map[string]any
+}
+
+// Pipeline chains post-processing effects, each one being a Kage shader.
+// Besides the parameters, every shader receives Time in seconds.
+type Pipeline struct {
+	spec      string
+	passes    []*pass
+	uniforms  map[string]any
+	dir       string
+	modTimes  map[string]time.Time
+	tickCount int
+	// buffers hold the results of all passes but the last one, which draws straight onto the screen
+	buffers  [2]*ebiten.Image
+	vertices [4]ebiten.Vertex
+}
+
+// NewPipeline builds a pipeline from a preset name or a comma-separated list of effects.
+// Effects are looked up in dir first, if given, as <name>.kage files.
+func NewPipeline(spec string, params map[string]float64, dir string) (*Pipeline, error) {
+	p := &Pipeline{
+		spec:      "",
+		passes:    nil,
+		uniforms:  make(map[string]any, len(defaultUniforms)+len(params)+1),
+		dir:       dir,
+		modTimes:  make(map[string]time.Time),
+		tickCount: 0,
+	}
+	for name, value := range defaultUniforms {
+		p.uniforms[name] = value
+	}
+	for name, value := range params {
+		p.uniforms[uniformName(name)] = float32(value)
+	}
+	if err := p.SwitchTo(spec); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseParams parses shader parameters given as "name=value,name=value".
+func ParseParams(s string) (map[string]float64, error) {
+	params := make(map[string]float64)
+	for _, kv := range strings.Split(s, ",") {
+		if strings.TrimSpace(kv) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("shader parameter %q is not in name=value form", kv)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("shader parameter %q: %w", kv, err)
+		}
+		params[strings.TrimSpace(name)] = v
+	}
+	return params, nil
+}
+
+// uniformName matches known uniforms case-insensitively. Others are only capitalised,
+// since Kage uniforms have to be exported.
+func uniformName(name string) string {
+	for known := range defaultUniforms {
+		if strings.EqualFold(known, name) {
+			return known
+		}
+	}
+	r := []rune(name)
+	if len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+	}
+	return string(r)
+}
+
+func (p *Pipeline) SwitchTo(spec string) error {
+	names, ok := presets[spec]
+	if !ok {
+		names = strings.Split(spec, ",")
+	}
+	passes := make([]*pass, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		ps, err := p.newPass(name)
+		if err != nil {
+			return err
+		}
+		passes = append(passes, ps)
+	}
+	p.spec = spec
+	p.disposePasses()
+	p.passes = passes
+	log.Info().Str("shader", spec).Msg("Shader pipeline set up")
+	return nil
+}
+
+// NextPreset switches to the preset which follows the current one, or to the first preset
+// if a custom list of effects is in use.
+func (p *Pipeline) NextPreset() {
+	next := PresetNames[(slices.Index(PresetNames, p.spec)+1)%len(PresetNames)]
+	if err := p.SwitchTo(next); err != nil {
+		log.Error().Err(err).Str("shader", next).Msg("Failed to switch shader preset")
+	}
+}
+
+func (p *Pipeline) newPass(name string) (*pass, error) {
+	src, ownUniforms, err := p.loadSource(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ebiten.NewShader(src)
 	if err != nil {
-		log.Fatal().Err(err).Send()
+		return nil, fmt.Errorf("shader %s: %w", name, err)
+	}
+	uniforms := make(map[string]any, len(p.uniforms)+len(ownUniforms)+1)
+	for n, value := range p.uniforms {
+		uniforms[n] = value
+	}
+	for n, value := range ownUniforms {
+		uniforms[n] = value
+	}
+	return &pass{
+		name:     name,
+		shader:   s,
+		uniforms: uniforms,
+	}, nil
+}
+
+func (p *Pipeline) loadSource(name string) ([]byte, map[string]any, error) {
+	if p.dir != "" {
+		path := p.path(name)
+		if info, err := os.Stat(path); err == nil {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			p.modTimes[path] = info.ModTime()
+			// an override of a built-in effect keeps its uniforms, such as the deficiency of a colour-blind one
+			return src, effects[name].uniforms, nil
+		}
+	}
+	e, ok := effects[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown shader effect %q", name)
+	}
+	src, err := builtinSources.ReadFile(e.file)
+	return src, e.uniforms, err
+}
+
+func (p *Pipeline) path(name string) string {
+	return filepath.Join(p.dir, name+".kage")
+}
+
+// Update reloads the effects whose files have changed on disk, checking them once a second.
+func (p *Pipeline) Update() {
+	p.tickCount++
+	if p.dir == "" || p.tickCount%ebiten.TPS() != 0 {
+		return
+	}
+	for i, ps := range p.passes {
+		path := p.path(ps.name)
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Equal(p.modTimes[path]) {
+			continue
+		}
+		reloaded, err := p.newPass(ps.name)
+		if err != nil {
+			// keeping the previous version, so that a typo doesn't black out the screen
+			p.modTimes[path] = info.ModTime()
+			log.Error().Err(err).Str("path", path).Msg("Failed to reload shader")
+			continue
+		}
+		ps.shader.Deallocate()
+		p.passes[i] = reloaded
+		log.Info().Str("path", path).Msg("Shader reloaded")
+	}
+}
+
+func (p *Pipeline) disposePasses() {
+	for _, ps := range p.passes {
+		ps.shader.Deallocate()
+	}
+}
+
+// DiscardBuffers releases the intermediate images. They get recreated on the next Apply.
+func (p *Pipeline) DiscardBuffers() {
+	for i, buf := range p.buffers {
+		if buf != nil {
+			buf.Deallocate()
+			p.buffers[i] = nil
+		}
+	}
+}
+
+// Apply runs the effects on src, the last one drawing straight onto dst transformed by geoM.
+// With no effects, src is just drawn onto dst.
+func (p *Pipeline) Apply(dst, src *ebiten.Image, geoM ebiten.GeoM, filter ebiten.Filter) {
+	if len(p.passes) == 0 {
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM = geoM
+		opts.Filter = filter
+		dst.DrawImage(src, opts)
+		return
+	}
+	seconds := float32(p.tickCount) / float32(ebiten.TPS())
+	input := src
+	for i, ps := range p.passes {
+		ps.uniforms["Time"] = seconds
+		if i == len(p.passes)-1 {
+			p.drawLastPass(dst, input, ps, geoM)
+			break
+		}
+		buf := p.buffer(i%2, src.Bounds())
+		opts := &ebiten.DrawRectShaderOptions{}
+		opts.Images[0] = input
+		opts.Uniforms = ps.uniforms
+		buf.DrawRectShader(buf.Bounds().Dx(), buf.Bounds().Dy(), ps.shader, opts)
+		input = buf
+	}
+}
+
+func (p *Pipeline) drawLastPass(dst, src *ebiten.Image, ps *pass, geoM ebiten.GeoM) {
+	b := src.Bounds()
+	corners := [4][2]int{{b.Min.X, b.Min.Y}, {b.Max.X, b.Min.Y}, {b.Min.X, b.Max.Y}, {b.Max.X, b.Max.Y}}
+	for i, c := range corners {
+		x, y := geoM.Apply(float64(c[0]-b.Min.X), float64(c[1]-b.Min.Y))
+		p.vertices[i] = ebiten.Vertex{
+			DstX:   float32(x),
+			DstY:   float32(y),
+			SrcX:   float32(c[0]),
+			SrcY:   float32(c[1]),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		}
+	}
+	opts := &ebiten.DrawTrianglesShaderOptions{}
+	opts.Images[0] = src
+	opts.Uniforms = ps.uniforms
+	dst.DrawTrianglesShader(p.vertices[:], quadIndices, ps.shader, opts)
+}
+
+var quadIndices = []uint16{0, 1, 2, 1, 2, 3}
+
+// buffer returns an intermediate image, recreating it only when the size of the source changes.
+func (p *Pipeline) buffer(i int, bounds image.Rectangle) *ebiten.Image {
+	buf := p.buffers[i]
+	if buf == nil || buf.Bounds().Size() != bounds.Size() {
+		if buf != nil {
+			buf.Deallocate()
+		}
+		buf = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+		p.buffers[i] = buf
 	}
-	return s
+	return buf
 }
@@ -0,0 +1,114 @@
+package sound
+
+import (
+	"snakehem/assets/sound/synth"
+	"snakehem/model"
+	"time"
+)
+
+// rest stands for a gap in the melodies below, which are otherwise given in semitones from A4.
+const rest = 1000
+
+func melody(waveform synth.Waveform, step time.Duration, volume float64, semitones ...int) synth.Sound {
+	s := make(synth.Sound, len(semitones))
+	for i, semitone := range semitones {
+		s[i] = synth.Note{
+			Waveform: waveform,
+			StartHz:  synth.Hz(semitone),
+			Duration: step,
+			Volume:   volume,
+			Envelope: synth.Pluck,
+		}
+		if semitone == rest {
+			s[i] = synth.Note{Waveform: synth.Rest, Duration: step}
+		}
+	}
+	return s
+}
+
+// countdownSound rises in pitch as the seconds run out.
+func countdownSound(seconds int) []synth.Sound {
+	return []synth.Sound{{{
+		Waveform: synth.Square,
+		StartHz:  synth.Hz(7 - 2*seconds),
+		Duration: 120 * time.Millisecond,
+		Volume:   0.4,
+		Envelope: synth.Pluck,
+	}}}
+}
+
+var goSound = []synth.Sound{{{
+	Waveform: synth.Square,
+	StartHz:  synth.Hz(12),
+	Duration: 300 * time.Millisecond,
+	Volume:   0.45,
+	Envelope: synth.Envelope{Attack: 5 * time.Millisecond, Release: 80 * time.Millisecond},
+}}}
+
+var turnSound = []synth.Sound{{{
+	Waveform: synth.Square,
+	StartHz:  synth.Hz(15),
+	Duration: 25 * time.Millisecond,
+	Volume:   0.15,
+	Duty:     0.125,
+	Envelope: synth.Envelope{Release: 10 * time.Millisecond},
+}}}
+
+// biteSound is higher the more health the bitten link has left, so a dull crunch warns of a nip.
+func biteSound(healthPercent int) []synth.Sound {
+	hz := synth.Hz(-24 + 24*max(0, healthPercent)/100)
+	return []synth.Sound{
+		{{
+			Waveform: synth.Noise,
+			StartHz:  hz * 16,
+			Duration: 70 * time.Millisecond,
+			Volume:   0.35,
+			Envelope: synth.Envelope{Decay: 70 * time.Millisecond},
+		}},
+		{{
+			Waveform: synth.Square,
+			StartHz:  hz,
+			EndHz:    hz / 2,
+			Duration: 70 * time.Millisecond,
+			Volume:   0.2,
+			Envelope: synth.Envelope{Release: 20 * time.Millisecond},
+		}},
+	}
+}
+
+// nipSound falls further and for longer the more of the tail has been nipped off.
+func nipSound(tailLength int) []synth.Sound {
+	length := min(tailLength, model.SnakeTargetLength)
+	return []synth.Sound{{{
+		Waveform: synth.Square,
+		StartHz:  synth.Hz(7),
+		EndHz:    synth.Hz(-12 - 24*length/model.SnakeTargetLength),
+		Duration: time.Duration(200+300*length/model.SnakeTargetLength) * time.Millisecond,
+		Volume:   0.45,
+		Envelope: synth.Pluck,
+	}}}
+}
+
+var appleSound = []synth.Sound{melody(synth.Square, 50*time.Millisecond, 0.35, 3, 7, 10, 15)}
+
+var warningSound = []synth.Sound{melody(synth.Square, 100*time.Millisecond, 0.35, 14, 9, 14, 9)}
+
+var gameOverSound = []synth.Sound{
+	melody(synth.Square, 250*time.Millisecond, 0.35, 10, 7, 3, 3),
+	melody(synth.Triangle, 500*time.Millisecond, 0.4, -14, -21),
+}
+
+var lobbyMusic = []synth.Sound{
+	melody(synth.Triangle, 250*time.Millisecond, 0.25,
+		-9, -5, -2, -5, -12, -9, -5, -9, -16, -12, -9, -12, -14, -10, -7, -10),
+	melody(synth.Triangle, time.Second, 0.3, -33, -36, -40, -38),
+}
+
+var actionMusic = []synth.Sound{
+	melody(synth.Square, 125*time.Millisecond, 0.15,
+		-24, rest, -24, -21, -24, rest, -17, -19, -26, rest, -26, -22, -26, rest, -19, -21,
+		-24, rest, -24, -21, -24, rest, -17, -19, -26, rest, -26, -22, -26, rest, -19, -21),
+	melody(synth.Noise, 125*time.Millisecond, 0.1,
+		36, rest, 48, rest, 36, rest, 48, rest, 36, rest, 48, rest, 36, rest, 48, 48,
+		36, rest, 48, rest, 36, rest, 48, rest, 36, rest, 48, rest, 36, 36, 48, 48),
+}
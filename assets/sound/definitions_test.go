@@ -0,0 +1,24 @@
+package sound
+
+import (
+	"snakehem/assets/sound/synth"
+	"testing"
+)
+
+func TestBitePitchRisesWithHealth(t *testing.T) {
+	previous := -1
+	for _, health := range []int{0, 25, 50, 75, 100} {
+		// the second voice is the tone, the first one is noise
+		samples := synth.Samples(sampleRate, biteSound(health)[1])
+		cycles := 0
+		for i := 1; i < len(samples); i++ {
+			if samples[i-1] < 0 && samples[i] >= 0 {
+				cycles++
+			}
+		}
+		if cycles <= previous {
+			t.Errorf("health %d%%: %d cycles, no more than %d at the lower health", health, cycles, previous)
+		}
+		previous = cycles
+	}
+}
@@ -0,0 +1,181 @@
+package sound
+
+import (
+	"bytes"
+	"snakehem/assets/sound/synth"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/rs/zerolog/log"
+)
+
+const sampleRate = 44100
+
+// volumeStep is how much a volume changes with one key press.
+const volumeStep = 0.25
+
+type Effect uint8
+
+const (
+	// Countdown is played at THREE, TWO and ONE, varying with the seconds left.
+	Countdown Effect = iota
+	// Go is played when the countdown is over.
+	Go
+	Turn
+	// Bite varies with the health percent the bitten link has left.
+	Bite
+	// Nip varies with the length of the nipped tail.
+	Nip
+	Apple
+	// Warning is played when a snake gets close enough to the target score to win with a single nip.
+	Warning
+	GameOver
+)
+
+// definitions make the voices of each effect for a given variant, which most effects ignore.
+var definitions = []func(variant int) []synth.Sound{
+	Countdown: countdownSound,
+	Go:        fixed(goSound),
+	Turn:      fixed(turnSound),
+	Bite:      biteSound,
+	Nip:       nipSound,
+	Apple:     fixed(appleSound),
+	Warning:   fixed(warningSound),
+	GameOver:  fixed(gameOverSound),
+}
+
+func fixed(voices []synth.Sound) func(int) []synth.Sound {
+	return func(int) []synth.Sound {
+		return voices
+	}
+}
+
+type variantKey struct {
+	effect  Effect
+	variant int
+}
+
+type Music uint8
+
+const (
+	NoMusic Music = iota
+	LobbyMusic
+	ActionMusic
+)
+
+var musicDefinitions = [][]synth.Sound{
+	NoMusic:     nil,
+	LobbyMusic:  lobbyMusic,
+	ActionMusic: actionMusic,
+}
+
+// Mixer plays sound effects over a music loop, all synthesised when the game starts or an effect is first needed.
+// Effects and music have separate volumes, from 0 to 1.
+type Mixer struct {
+	context *audio.Context
+	// rendered effects are kept, since a new player is made every time an effect is played
+	rendered      map[variantKey][]byte
+	music         []*audio.Player
+	currentMusic  Music
+	effectsVolume float64
+	musicVolume   float64
+	muted         bool
+}
+
+func NewMixer(muted bool, effectsVolume, musicVolume float64) *Mixer {
+	m := &Mixer{
+		context:       audio.NewContext(sampleRate),
+		rendered:      make(map[variantKey][]byte),
+		music:         make([]*audio.Player, len(musicDefinitions)),
+		currentMusic:  NoMusic,
+		effectsVolume: clampVolume(effectsVolume),
+		musicVolume:   clampVolume(musicVolume),
+		muted:         muted,
+	}
+	for i, voices := range musicDefinitions {
+		if voices == nil {
+			continue
+		}
+		pcm := synth.Render(sampleRate, voices...)
+		// a bytes.Reader never fails to seek, so the player can't fail to be made either
+		player, _ := m.context.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm))))
+		m.music[i] = player
+	}
+	m.applyMusicVolume()
+	return m
+}
+
+func (m *Mixer) Play(effect Effect) {
+	m.PlayVariant(effect, 0)
+}
+
+// PlayVariant plays an effect which varies with the state of the game, see the effect constants.
+func (m *Mixer) PlayVariant(effect Effect, variant int) {
+	if m.muted || m.effectsVolume == 0 {
+		return
+	}
+	key := variantKey{effect: effect, variant: variant}
+	pcm, ok := m.rendered[key]
+	if !ok {
+		pcm = synth.Render(sampleRate, definitions[effect](variant)...)
+		m.rendered[key] = pcm
+	}
+	player := m.context.NewPlayerFromBytes(pcm)
+	player.SetVolume(m.effectsVolume)
+	player.Play()
+}
+
+// PlayMusic switches to another loop, which carries on from where it was left off. Asking for
+// the loop which is already playing does nothing, so this can be called on every tick.
+func (m *Mixer) PlayMusic(music Music) {
+	if music == m.currentMusic {
+		return
+	}
+	if player := m.music[m.currentMusic]; player != nil {
+		player.Pause()
+	}
+	m.currentMusic = music
+	if player := m.music[music]; player != nil {
+		player.Play()
+	}
+}
+
+func (m *Mixer) ToggleMute() {
+	m.muted = !m.muted
+	m.applyMusicVolume()
+	log.Info().Bool("muted", m.muted).Msg("Sound toggled")
+}
+
+// CycleEffectsVolume turns the volume up by volumeStep, going back to silence after the loudest.
+func (m *Mixer) CycleEffectsVolume() {
+	m.effectsVolume = nextVolume(m.effectsVolume)
+	log.Info().Float64("volume", m.effectsVolume).Msg("Sound effects volume changed")
+}
+
+func (m *Mixer) CycleMusicVolume() {
+	m.musicVolume = nextVolume(m.musicVolume)
+	m.applyMusicVolume()
+	log.Info().Float64("volume", m.musicVolume).Msg("Music volume changed")
+}
+
+func (m *Mixer) applyMusicVolume() {
+	volume := m.musicVolume
+	if m.muted {
+		volume = 0
+	}
+	for _, player := range m.music {
+		if player != nil {
+			player.SetVolume(volume)
+		}
+	}
+}
+
+func nextVolume(volume float64) float64 {
+	if volume >= 1 {
+		return 0
+	}
+	return clampVolume(volume + volumeStep)
+}
+
+func clampVolume(volume float64) float64 {
+	return max(0, min(1, volume))
+}
@@ -0,0 +1,195 @@
+package synth
+
+import (
+	"math"
+	"time"
+)
+
+// Waveform is the shape of the wave a note is played with, after the sound chips of old consoles.
+type Waveform uint8
+
+const (
+	Square Waveform = iota
+	Triangle
+	// Noise is white noise from a linear-feedback shift register, pitched by how often it's shifted.
+	Noise
+	// Rest is silence, for gaps in melodies.
+	Rest
+)
+
+// Envelope shapes the volume of a note. The release is taken from the end of the note,
+// so that notes keep their durations.
+type Envelope struct {
+	Attack  time.Duration
+	Decay   time.Duration
+	Sustain float64
+	Release time.Duration
+}
+
+// Pluck is a short attack with a slight decay, good for most effects.
+var Pluck = Envelope{
+	Attack:  5 * time.Millisecond,
+	Decay:   40 * time.Millisecond,
+	Sustain: 0.7,
+	Release: 30 * time.Millisecond,
+}
+
+// Note is a tone which slides from StartHz to EndHz over its duration. EndHz is zero for a steady tone.
+type Note struct {
+	Waveform Waveform
+	StartHz  float64
+	EndHz    float64
+	Duration time.Duration
+	Volume   float64
+	// Duty is the part of the period a square wave is high, a half if zero
+	Duty     float64
+	Envelope Envelope
+}
+
+// Sound is a sequence of notes played one after another.
+type Sound []Note
+
+func (s Sound) Duration() time.Duration {
+	var d time.Duration
+	for _, n := range s {
+		d += n.Duration
+	}
+	return d
+}
+
+// Render turns sounds into 16-bit little-endian stereo PCM, which is what ebiten's audio players take.
+// Sounds given together are mixed, as the voices of a tune, and the result lasts as long as the longest one.
+func Render(sampleRate int, sounds ...Sound) []byte {
+	samples := Mix(sampleRate, sounds...)
+	pcm := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		v := int16(math.Round(max(-1, min(1, s)) * math.MaxInt16))
+		pcm[i*4] = byte(v)
+		pcm[i*4+1] = byte(v >> 8)
+		pcm[i*4+2] = byte(v)
+		pcm[i*4+3] = byte(v >> 8)
+	}
+	return pcm
+}
+
+// Mix adds up the samples of the sounds, from -1 to 1 unless they are loud enough to clip.
+func Mix(sampleRate int, sounds ...Sound) []float64 {
+	var result []float64
+	for _, s := range sounds {
+		samples := Samples(sampleRate, s)
+		if len(samples) > len(result) {
+			result = append(result, make([]float64, len(samples)-len(result))...)
+		}
+		for i, v := range samples {
+			result[i] += v
+		}
+	}
+	return result
+}
+
+// Samples renders a single sound as mono samples from -1 to 1.
+func Samples(sampleRate int, s Sound) []float64 {
+	result := make([]float64, 0, samplesIn(s.Duration(), sampleRate))
+	for _, n := range s {
+		result = n.appendSamples(result, sampleRate)
+	}
+	return result
+}
+
+func (n Note) appendSamples(dst []float64, sampleRate int) []float64 {
+	count := samplesIn(n.Duration, sampleRate)
+	if n.Waveform == Rest {
+		return append(dst, make([]float64, count)...)
+	}
+	duty := n.Duty
+	if duty == 0 {
+		duty = 0.5
+	}
+	endHz := n.EndHz
+	if endHz == 0 {
+		endHz = n.StartHz
+	}
+	var phase float64
+	noise := newLfsr()
+	for i := 0; i < count; i++ {
+		progress := float64(i) / float64(count)
+		hz := n.StartHz + (endHz-n.StartHz)*progress
+		var v float64
+		switch n.Waveform {
+		case Square:
+			v = 1
+			if phase >= duty {
+				v = -1
+			}
+		case Triangle:
+			v = 4*math.Abs(phase-0.5) - 1
+		case Noise:
+			v = noise.value
+		case Rest:
+		}
+		phase += hz / float64(sampleRate)
+		for phase >= 1 {
+			phase--
+			noise.shift()
+		}
+		dst = append(dst, v*n.Volume*n.Envelope.level(i, count, sampleRate))
+	}
+	return dst
+}
+
+// level is the volume of the envelope at the i-th of count samples, from 0 to 1.
+// An envelope with no attack, decay and release is flat at full volume.
+func (e Envelope) level(i, count, sampleRate int) float64 {
+	attack := samplesIn(e.Attack, sampleRate)
+	decay := samplesIn(e.Decay, sampleRate)
+	release := samplesIn(e.Release, sampleRate)
+	sustain := e.Sustain
+	if decay == 0 && e.Sustain == 0 {
+		sustain = 1
+	}
+	level := sustain
+	switch {
+	case i < attack:
+		level = float64(i) / float64(attack)
+	case i < attack+decay:
+		level = 1 - (1-sustain)*float64(i-attack)/float64(decay)
+	}
+	if left := count - i; left < release {
+		level *= float64(left) / float64(release)
+	}
+	return level
+}
+
+func samplesIn(d time.Duration, sampleRate int) int {
+	return int(d * time.Duration(sampleRate) / time.Second)
+}
+
+// lfsr is the 15-bit shift register of the NES noise channel. It always starts from the same state,
+// so that a sound is rendered the same every time. The state is a mix of ones and zeros rather than
+// the 1 of the NES, which would keep the output flat until the bit shifts all the way down.
+type lfsr struct {
+	state uint16
+	value float64
+}
+
+func newLfsr() *lfsr {
+	return &lfsr{
+		state: 0x5a3c,
+		value: 1,
+	}
+}
+
+func (l *lfsr) shift() {
+	bit := (l.state ^ l.state>>1) & 1
+	l.state = l.state>>1 | bit<<14
+	if l.state&1 == 0 {
+		l.value = 1
+	} else {
+		l.value = -1
+	}
+}
+
+// Hz is the frequency of a note given in semitones from A4, which is 440 Hz.
+func Hz(semitonesFromA4 int) float64 {
+	return 440 * math.Pow(2, float64(semitonesFromA4)/12)
+}
@@ -0,0 +1,128 @@
+package synth
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+const sampleRate = 44100
+
+// channels splits 16-bit little-endian stereo PCM into its channels.
+func channels(pcm []byte) (left, right []int16) {
+	for i := 0; i+3 < len(pcm); i += 4 {
+		left = append(left, int16(binary.LittleEndian.Uint16(pcm[i:])))
+		right = append(right, int16(binary.LittleEndian.Uint16(pcm[i+2:])))
+	}
+	return left, right
+}
+
+// cycles counts the times a signal goes from below zero to zero or above.
+func cycles[T int16 | float64](samples []T) int {
+	count := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRenderLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		sounds []Sound
+		frames int
+	}{
+		{"single note", []Sound{{{Waveform: Square, StartHz: 440, Duration: 250 * time.Millisecond, Volume: 1}}}, 11025},
+		{"sequence", []Sound{{
+			{Waveform: Square, StartHz: 440, Duration: 100 * time.Millisecond, Volume: 1},
+			{Waveform: Rest, Duration: 50 * time.Millisecond},
+		}}, 6615},
+		{"mix lasts as long as the longest voice", []Sound{
+			{{Waveform: Square, StartHz: 440, Duration: 100 * time.Millisecond, Volume: 1}},
+			{{Waveform: Triangle, StartHz: 220, Duration: time.Second, Volume: 1}},
+		}, 44100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pcm := Render(sampleRate, tt.sounds...)
+			if want := tt.frames * 4; len(pcm) != want {
+				t.Errorf("got %d bytes, want %d", len(pcm), want)
+			}
+		})
+	}
+}
+
+func TestRenderChannelsAreIdentical(t *testing.T) {
+	pcm := Render(
+		sampleRate,
+		Sound{{Waveform: Noise, StartHz: 2000, Duration: 100 * time.Millisecond, Volume: 0.5, Envelope: Pluck}},
+		Sound{{Waveform: Square, StartHz: 440, EndHz: 220, Duration: 100 * time.Millisecond, Volume: 0.5, Duty: 0.25}},
+	)
+	left, right := channels(pcm)
+	if !slices.Equal(left, right) {
+		t.Error("left and right channels differ")
+	}
+}
+
+func TestA4(t *testing.T) {
+	if got := Hz(0); got != 440 {
+		t.Errorf("Hz(0) = %v, want 440", got)
+	}
+	if got := Hz(12); math.Abs(got-880) > 1e-9 {
+		t.Errorf("Hz(12) = %v, want 880", got)
+	}
+	for _, waveform := range []Waveform{Square, Triangle} {
+		pcm := Render(sampleRate, Sound{{Waveform: waveform, StartHz: Hz(0), Duration: time.Second, Volume: 1}})
+		left, _ := channels(pcm)
+		if got := cycles(left); got < 439 || got > 441 {
+			t.Errorf("waveform %d: got %d cycles in a second, want 440", waveform, got)
+		}
+	}
+}
+
+func TestEnvelopeStaysInBounds(t *testing.T) {
+	envelopes := []Envelope{
+		Pluck,
+		{},
+		{Decay: 70 * time.Millisecond},
+		{Release: 20 * time.Millisecond},
+		{Attack: 30 * time.Millisecond, Decay: 30 * time.Millisecond, Sustain: 0.5, Release: 30 * time.Millisecond},
+	}
+	count := samplesIn(100*time.Millisecond, sampleRate)
+	for _, e := range envelopes {
+		for i := 0; i < count; i++ {
+			if level := e.level(i, count, sampleRate); level < 0 || level > 1 {
+				t.Fatalf("envelope %+v: level %v at sample %d", e, level, i)
+			}
+		}
+		const volume = 0.3
+		samples := Samples(sampleRate, Sound{{Waveform: Square, StartHz: 440, Duration: 100 * time.Millisecond, Volume: volume, Envelope: e}})
+		for i, s := range samples {
+			if math.Abs(s) > volume {
+				t.Fatalf("envelope %+v: sample %v at %d is louder than the volume", e, s, i)
+			}
+		}
+	}
+	if level := Pluck.level(0, count, sampleRate); level != 0 {
+		t.Errorf("attack starts at %v, want 0", level)
+	}
+	if level := Pluck.level(count-1, count, sampleRate); level > 0.01 {
+		t.Errorf("release ends at %v, want about 0", level)
+	}
+}
+
+func TestNoiseIsDeterministic(t *testing.T) {
+	noise := Sound{{Waveform: Noise, StartHz: 4000, Duration: 50 * time.Millisecond, Volume: 1}}
+	first := Render(sampleRate, noise)
+	second := Render(sampleRate, noise)
+	if !slices.Equal(first, second) {
+		t.Error("noise renders differently every time")
+	}
+	if samples := Samples(sampleRate, noise); cycles(samples) == 0 {
+		t.Error("noise is flat")
+	}
+}
@@ -0,0 +1,340 @@
+package theme
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"snakehem/assets/adhoc8"
+	"snakehem/assets/pxterm16"
+	"snakehem/assets/pxterm24"
+	"snakehem/model"
+	"strings"
+
+	"github.com/pbnjay/pixfont"
+	"github.com/rs/zerolog/log"
+)
+
+//go:embed *.json
+var builtinThemes embed.FS
+
+// builtinOrder is the order the built-in themes are cycled through. User themes follow them.
+var builtinOrder = []string{"classic", "night", "paper", "high-contrast"}
+
+// MinContrast is the lowest contrast ratio between a snake and the background which isn't warned about.
+// It's the WCAG minimum for graphical objects.
+const MinContrast = 3
+
+var fonts = map[string]*pixfont.PixFont{
+	"pxterm16": pxterm16.Font,
+	"pxterm24": pxterm24.Font,
+	"adhoc8":   adhoc8.Font,
+}
+
+type Theme struct {
+	Name       string
+	Background color.Color
+	// GridLines are drawn between the cells, nil for none
+	GridLines color.Color
+	Apple     color.Color
+	// Text is the colour of prompts and titles
+	Text color.Color
+	// Highlight is the colour of the selected or hovered items
+	Highlight color.Color
+	// Info is the colour of other text, like the time elapsed
+	Info         color.Color
+	SnakeColours [model.MaxSnakes]color.Color
+	// TextFont is the font of prompts, hints and tables, and ScoreFont the large one of scores, titles and menus
+	TextFont  *pixfont.PixFont
+	ScoreFont *pixfont.PixFont
+}
+
+// definition is how a theme is stored in JSON. Colours are in #rrggbb form, fonts are named after
+// the assets they come from.
+type definition struct {
+	Name       string   `json:"name"`
+	Background string   `json:"background"`
+	GridLines  string   `json:"gridLines,omitempty"`
+	Apple      string   `json:"apple"`
+	Text       string   `json:"text"`
+	Highlight  string   `json:"highlight"`
+	Info       string   `json:"info"`
+	Snakes     []string `json:"snakes"`
+	TextFont   string   `json:"textFont"`
+	ScoreFont  string   `json:"scoreFont"`
+}
+
+var (
+	themes  []*Theme
+	current int
+	// resolved is the current theme with the palette replaced in the colour-blind mode
+	resolved   *Theme
+	deficiency Deficiency
+)
+
+// Deficiency is a kind of colour blindness the game adapts to.
+type Deficiency uint8
+
+const (
+	NoDeficiency Deficiency = iota
+	Protanopia
+	Deuteranopia
+	Tritanopia
+)
+
+var deficiencyNames = []string{"none", "protanopia", "deuteranopia", "tritanopia"}
+
+// okabeIto is the palette by Masataka Okabe and Kei Ito, which is safe for both kinds of red-green
+// colour blindness. White stands in for black, and grey is added to get enough colours.
+var okabeIto = [model.MaxSnakes]color.Color{
+	color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	color.NRGBA{R: 0xe6, G: 0x9f, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0x56, G: 0xb4, B: 0xe9, A: 0xff},
+	color.NRGBA{R: 0x00, G: 0x9e, B: 0x73, A: 0xff},
+	color.NRGBA{R: 0xf0, G: 0xe4, B: 0x42, A: 0xff},
+	color.NRGBA{R: 0x00, G: 0x72, B: 0xb2, A: 0xff},
+	color.NRGBA{R: 0xd5, G: 0x5e, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0xcc, G: 0x79, B: 0xa7, A: 0xff},
+	color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff},
+}
+
+// tritanPalette avoids the blue-green and yellow-violet pairs, which tritanopes confuse.
+var tritanPalette = [model.MaxSnakes]color.Color{
+	color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	color.NRGBA{R: 0xe4, G: 0x1a, B: 0x1c, A: 0xff},
+	color.NRGBA{R: 0x00, G: 0xc2, B: 0xc2, A: 0xff},
+	color.NRGBA{R: 0xff, G: 0x8a, B: 0xd8, A: 0xff},
+	color.NRGBA{R: 0x99, G: 0x99, B: 0x99, A: 0xff},
+	color.NRGBA{R: 0x8b, G: 0x00, B: 0x00, A: 0xff},
+	color.NRGBA{R: 0x00, G: 0x7a, B: 0x7a, A: 0xff},
+	color.NRGBA{R: 0xc5, G: 0x1b, B: 0x8a, A: 0xff},
+	color.NRGBA{R: 0xff, G: 0xc8, B: 0xc8, A: 0xff},
+}
+
+// ParseDeficiency accepts an empty string as well as "none" for no deficiency.
+func ParseDeficiency(s string) (Deficiency, error) {
+	if s == "" {
+		return NoDeficiency, nil
+	}
+	idx := slices.Index(deficiencyNames, strings.ToLower(s))
+	if idx == -1 {
+		return NoDeficiency, fmt.Errorf("unknown colour blindness %q, expected one of %s", s, strings.Join(deficiencyNames, ", "))
+	}
+	return Deficiency(idx), nil
+}
+
+func (d Deficiency) String() string {
+	return deficiencyNames[d]
+}
+
+// SetDeficiency turns the colour-blind mode on, unless d is NoDeficiency. In this mode, snakes get
+// a palette suited for the deficiency, body patterns and numbers, and damage is outlined instead of reddened.
+func SetDeficiency(d Deficiency) {
+	deficiency = d
+	switchTo(current)
+}
+
+func IsColourBlindMode() bool {
+	return deficiency != NoDeficiency
+}
+
+// DefaultDir is where user themes are looked for, unless told otherwise.
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "snakehem", "themes")
+}
+
+func init() {
+	for _, name := range builtinOrder {
+		data, err := builtinThemes.ReadFile(name + ".json")
+		if err == nil {
+			err = add(data)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("built-in theme %s: %v", name, err))
+		}
+	}
+}
+
+// Current is never nil: the classic theme is used until another one is chosen.
+func Current() *Theme {
+	if resolved == nil {
+		return themes[current]
+	}
+	return resolved
+}
+
+// LoadDir adds the themes found as .json files in a directory. A user theme with the name of
+// a built-in one replaces it. Broken files are skipped with a warning.
+func LoadDir(dir string) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		log.Warn().Err(err).Str("dir", dir).Msg("Failed to list themes")
+		return
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = add(data)
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("path", path).Msg("Failed to load theme")
+			continue
+		}
+		log.Info().Str("path", path).Msg("Theme loaded")
+	}
+}
+
+func add(data []byte) error {
+	var d definition
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	t, err := d.theme()
+	if err != nil {
+		return err
+	}
+	if idx := slices.IndexFunc(themes, func(other *Theme) bool { return other.Name == t.Name }); idx != -1 {
+		themes[idx] = t
+	} else {
+		themes = append(themes, t)
+	}
+	return nil
+}
+
+func (d definition) theme() (*Theme, error) {
+	if d.Name == "" {
+		return nil, fmt.Errorf("theme has no name")
+	}
+	if len(d.Snakes) != model.MaxSnakes {
+		return nil, fmt.Errorf("theme %s has %d snake colours instead of %d", d.Name, len(d.Snakes), model.MaxSnakes)
+	}
+	t := &Theme{Name: d.Name}
+	var errs []error
+	parse := func(s string) color.Color {
+		c, err := ParseColour(s)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return c
+	}
+	t.Background = parse(d.Background)
+	if d.GridLines != "" {
+		t.GridLines = parse(d.GridLines)
+	}
+	t.Apple = parse(d.Apple)
+	t.Text = parse(d.Text)
+	t.Highlight = parse(d.Highlight)
+	t.Info = parse(d.Info)
+	for i, s := range d.Snakes {
+		t.SnakeColours[i] = parse(s)
+	}
+	t.TextFont = fonts[d.TextFont]
+	t.ScoreFont = fonts[d.ScoreFont]
+	if t.TextFont == nil || t.ScoreFont == nil {
+		errs = append(errs, fmt.Errorf("unknown font, expected one of pxterm16, pxterm24, adhoc8"))
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("theme %s: %w", d.Name, errs[0])
+	}
+	return t, nil
+}
+
+func ParseColour(s string) (color.Color, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("colour %q is not in #rrggbb form", s)
+	}
+	return color.NRGBA{R: r, G: g, B: b, A: 255}, nil
+}
+
+// Names lists the available themes in the order they're cycled through.
+func Names() []string {
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+func SwitchTo(name string) error {
+	idx := slices.IndexFunc(themes, func(t *Theme) bool { return strings.EqualFold(t.Name, name) })
+	if idx == -1 {
+		return fmt.Errorf("unknown theme %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	switchTo(idx)
+	return nil
+}
+
+func Next() {
+	switchTo((current + 1) % len(themes))
+}
+
+func Previous() {
+	switchTo((current + len(themes) - 1) % len(themes))
+}
+
+func switchTo(idx int) {
+	current = idx
+	t := themes[idx]
+	switch deficiency {
+	case Protanopia, Deuteranopia:
+		t = t.withPalette(okabeIto)
+	case Tritanopia:
+		t = t.withPalette(tritanPalette)
+	case NoDeficiency:
+	}
+	resolved = t
+	log.Info().Str("theme", t.Name).Msg("Theme changed")
+	for _, i := range t.LowContrastSnakes() {
+		log.Warn().
+			Str("theme", t.Name).
+			Int("snakeColour", i).
+			Float64("contrast", Contrast(t.SnakeColours[i], t.Background)).
+			Msg("Snake colour is hard to tell from the background")
+	}
+}
+
+func (t *Theme) withPalette(palette [model.MaxSnakes]color.Color) *Theme {
+	result := *t
+	result.SnakeColours = palette
+	return &result
+}
+
+// LowContrastSnakes lists the indices of the snake colours which are too close to the background.
+func (t *Theme) LowContrastSnakes() []int {
+	var result []int
+	for i, c := range t.SnakeColours {
+		if Contrast(c, t.Background) < MinContrast {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Contrast is the WCAG contrast ratio of two colours, from 1 (same luminance) to 21 (black and white).
+func Contrast(a, b color.Color) float64 {
+	la := luminance(a)
+	lb := luminance(b)
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
+
+func luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+func linear(channel uint32) float64 {
+	v := float64(channel) / 0xffff
+	if v <= 0.03928 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
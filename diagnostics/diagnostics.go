@@ -0,0 +1,121 @@
+package diagnostics
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	runtimepprof "runtime/pprof"
+	"runtime/trace"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CaptureDuration is how long the CPU profile and the execution trace taken with F1 run for.
+const CaptureDuration = 10 * time.Second
+
+// ServePprof serves the profiles of net/http/pprof at /debug/pprof/ on a given address, in the background.
+func ServePprof(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Error().Err(err).Str("addr", addr).Msg("Pprof server stopped")
+		}
+	}()
+	log.Info().Str("addr", listener.Addr().String()).Msg("Serving pprof")
+	return nil
+}
+
+// Prefix names the files of a capture or a dump in a given directory after the current time,
+// creating the directory if needed.
+func Prefix(dir, name string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+"-"+time.Now().Format("20060102-150405")), nil
+}
+
+// capturing is set from the start of a capture until its files are written.
+var capturing atomic.Bool
+
+// Capture records a CPU profile, and an execution trace if asked for, in the background. The files are
+// named after a prefix and written once the duration has passed. Only one CPU profile can run at a time,
+// so nothing is captured while another one is running, including one requested over pprof. The files of
+// a running capture are never touched, even by another one asked for within the same second.
+func Capture(prefix string, duration time.Duration, withTrace bool) {
+	if !capturing.CompareAndSwap(false, true) {
+		log.Warn().Msg("A capture is running already")
+		return
+	}
+	cpuPath := prefix + "-cpu.pprof"
+	cpuFile, err := create(cpuPath)
+	if err != nil {
+		log.Error().Err(err).Str("path", cpuPath).Msg("Failed to create the CPU profile")
+		capturing.Store(false)
+		return
+	}
+	if err := runtimepprof.StartCPUProfile(cpuFile); err != nil {
+		log.Warn().Err(err).Msg("Failed to start the CPU profile")
+		cpuFile.Close()
+		os.Remove(cpuPath)
+		capturing.Store(false)
+		return
+	}
+	log.Info().Str("path", cpuPath).Dur("duration", duration).Msg("CPU profile started")
+	var traceFile *os.File
+	tracePath := prefix + ".trace"
+	if withTrace {
+		traceFile = startTrace(tracePath)
+	}
+	time.AfterFunc(duration, func() {
+		runtimepprof.StopCPUProfile()
+		closeFile(cpuFile, cpuPath)
+		if traceFile != nil {
+			trace.Stop()
+			closeFile(traceFile, tracePath)
+		}
+		capturing.Store(false)
+	})
+}
+
+// create fails rather than truncating a file which is there already.
+func create(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+}
+
+// startTrace returns nil if the trace can't be started, in which case the CPU profile goes on without it.
+func startTrace(path string) *os.File {
+	f, err := create(path)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to create the execution trace")
+		return nil
+	}
+	if err := trace.Start(f); err != nil {
+		log.Warn().Err(err).Msg("Failed to start the execution trace")
+		f.Close()
+		os.Remove(path)
+		return nil
+	}
+	log.Info().Str("path", path).Msg("Execution trace started")
+	return f
+}
+
+func closeFile(f *os.File, path string) {
+	if err := f.Close(); err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to write the capture")
+		return
+	}
+	log.Info().Str("path", path).Msg("Capture written")
+}
@@ -0,0 +1,99 @@
+package display
+
+import (
+	"image/color"
+	"math"
+	"snakehem/model"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// viewport places the square frame the game is drawn on onto the actual screen,
+// which may be of any size and pixel density.
+type viewport struct {
+	scale   float64
+	offsetX float64
+	offsetY float64
+}
+
+var current = viewport{
+	scale:   1,
+	offsetX: 0,
+	offsetY: 0,
+}
+
+// Layout makes the screen match the physical pixels of the monitor, so that the frame
+// can be scaled up by a whole number without any blurring by the OS.
+func Layout(outsideWidth, outsideHeight int) (int, int) {
+	scaleFactor := 1.0
+	if m := ebiten.Monitor(); m != nil {
+		scaleFactor = m.DeviceScaleFactor()
+	}
+	w := int(math.Ceil(float64(outsideWidth) * scaleFactor))
+	h := int(math.Ceil(float64(outsideHeight) * scaleFactor))
+	current = fit(w, h)
+	return w, h
+}
+
+// fit picks the largest integer scale that makes the frame fit the screen, centering
+// the frame and leaving letterboxes around it. Only when the screen is smaller than
+// the frame, the latter is scaled down by a fraction.
+func fit(screenWidth, screenHeight int) viewport {
+	side := min(screenWidth, screenHeight)
+	scale := float64(side / model.GridDimPx)
+	if scale < 1 {
+		scale = float64(side) / model.GridDimPx
+	}
+	frameSide := model.GridDimPx * scale
+	return viewport{
+		scale:   scale,
+		offsetX: math.Floor((float64(screenWidth) - frameSide) / 2),
+		offsetY: math.Floor((float64(screenHeight) - frameSide) / 2),
+	}
+}
+
+// Clear blackens the whole screen, including the letterboxes.
+func Clear(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+}
+
+// DrawFrame draws an image of the frame size onto the screen, where the frame belongs.
+func DrawFrame(screen, frame *ebiten.Image) {
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM = GeoM()
+	opts.Filter = Filter()
+	screen.DrawImage(frame, opts)
+}
+
+// GeoM transforms the frame coordinates to the screen ones.
+func GeoM() ebiten.GeoM {
+	var geoM ebiten.GeoM
+	geoM.Scale(current.scale, current.scale)
+	geoM.Translate(current.offsetX, current.offsetY)
+	return geoM
+}
+
+// Filter keeps pixels sharp, unless the frame has to be scaled down by a fraction.
+func Filter() ebiten.Filter {
+	if current.scale != math.Trunc(current.scale) {
+		return ebiten.FilterLinear
+	}
+	return ebiten.FilterNearest
+}
+
+// ToFrame converts a position on the screen, like the one of the mouse cursor or a touch,
+// to the one on the frame.
+func ToFrame(x, y int) (int, int) {
+	return int(math.Floor((float64(x) - current.offsetX) / current.scale)),
+		int(math.Floor((float64(y) - current.offsetY) / current.scale))
+}
+
+// DefaultWindowSize is the largest multiple of the frame size that comfortably fits the monitor.
+func DefaultWindowSize() (int, int) {
+	monitorHeight := 0
+	if m := ebiten.Monitor(); m != nil {
+		_, monitorHeight = m.Size()
+	}
+	side := max(1, monitorHeight*9/10/model.GridDimPx) * model.GridDimPx
+	return side, side
+}
@@ -2,64 +2,79 @@ package common
 
 import (
 	"fmt"
+	"image"
 	"image/color"
 	"math"
 	"snakehem/assets/adhoc8"
-	"snakehem/assets/pxterm24"
+	"snakehem/assets/theme"
 	"snakehem/model"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/pbnjay/pixfont"
-	"golang.org/x/image/colornames"
 )
 
 var ScoreFmt = "%0" + fmt.Sprint(int(math.Log10(model.TargetScore))+1) + "d"
-var Pxterm16Height = pxterm24.Font.GetHeight()
-var Pxterm24Height = pxterm24.Font.GetHeight()
 var Adhoc8Height = adhoc8.Font.GetHeight()
 
-const (
-	CellDimPx = 11
-	GridDimPx = model.GridSize * CellDimPx
-)
+// TextLineHeight is the distance between the lines of text drawn in the font of the current theme.
+func TextLineHeight() int {
+	return theme.Current().TextFont.GetHeight() * 3 / 2
+}
 
-var SnakeColours = [model.MaxSnakes]color.Color{
-	colornames.Lightgrey,
-	color.NRGBA{ // orange
-		R: 255,
-		G: 128,
-		B: 10,
-		A: 255,
-	},
-	colornames.Yellow,
-	color.NRGBA{ // dark green
-		R: 100,
-		G: 170,
-		B: 0,
-		A: 255,
-	},
-	colornames.Cyan,
-	colornames.Blue,
-	color.NRGBA{ // dark blue
-		R: 0,
-		G: 0,
-		B: 100,
-		A: 255,
-	},
-	color.NRGBA{ // dark magenta
-		R: 100,
-		G: 0,
-		B: 84,
-		A: 255,
-	},
-	colornames.Magenta,
+// ScoreHeight is the height of the large font of the current theme.
+func ScoreHeight() int {
+	return theme.Current().ScoreFont.GetHeight()
 }
 
+const (
+	CellDimPx = model.CellDimPx
+	GridDimPx = model.GridDimPx
+)
+
 func DrawTextCentered(screen *ebiten.Image, txt string, colour color.Color, top float64, font *pixfont.PixFont) {
 	txtWidth := font.MeasureString(txt)
 	font.DrawString(screen, (GridDimPx-txtWidth)/2, int(top), txt, colour)
 }
 
+// TextCenteredBounds returns the area covered by the non-blank part of a text drawn with DrawTextCentered.
+// This lets clickable words be highlighted and hit-tested, when they are drawn over a line of text
+// as a separate string padded with spaces.
+func TextCenteredBounds(txt string, top float64, font *pixfont.PixFont) image.Rectangle {
+	left := (GridDimPx - font.MeasureString(txt)) / 2
+	trimmed := strings.TrimLeft(txt, " ")
+	x := left + font.MeasureString(txt[:len(txt)-len(trimmed)])
+	return image.Rect(
+		x,
+		int(top),
+		x+font.MeasureString(strings.TrimRight(trimmed, " ")),
+		int(top)+font.GetHeight(),
+	)
+}
+
+func SameColour(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+// LinkColour is the colour a link of a snake is drawn with. In the colour-blind mode, the red hue
+// is left out, since damage is shown in other ways.
+func LinkColour(colour color.Color, redness float32) color.Color {
+	if theme.IsColourBlindMode() {
+		return colour
+	}
+	return WithRedness(colour, redness)
+}
+
+// ContrastingColour is either black or white, whichever is easier to see on a given colour.
+func ContrastingColour(colour color.Color) color.Color {
+	if theme.Contrast(colour, color.Black) > theme.Contrast(colour, color.White) {
+		return color.Black
+	}
+	return color.White
+}
+
 // WithRedness transforms a given colour by add a red hue to it. redness argument
 // varies from 0 (keep the original colour) to 1 (make it fully red).
 func WithRedness(colour color.Color, redness float32) color.Color {
@@ -1,41 +1,82 @@
 package game
 
 import (
+	"snakehem/display"
 	"snakehem/game/common"
 	"snakehem/model"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/rs/zerolog/log"
 )
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	if ebiten.Tick()%model.TpsMultiplier == 0 {
+	if g.sharedContent.Smooth || ebiten.Tick()%model.TpsMultiplier == 0 {
 		g.doDraw(screen)
 	}
 }
 
+// doDraw renders the shaded layers into the frame, lets the shaders draw it straight onto the screen,
+// shaken if needed, and then puts the unshaded overlay on top. No image is allocated, unless the buffers are churned.
 func (g *Game) doDraw(screen *ebiten.Image) {
 	start := time.Now()
 	defer func() {
 		g.unshadedContent.RecordDrawTimeAndFps(start)
 	}()
-	frame := ebiten.NewImage(common.GridDimPx, common.GridDimPx)
-	g.sharedContent.Draw(frame)
-	g.localContent.Draw(frame)
-	g.applyShader(frame)
-	g.unshadedContent.Draw(frame)
-	screen.DrawImage(frame, nil)
+	if g.churnBuffers {
+		g.discardBuffers()
+	}
+	g.frame = reuseImage(g.frame)
+	g.overlay = reuseImage(g.overlay)
+	g.frame.Clear()
+	g.sharedContent.Draw(g.frame)
+	g.effectsContent.Draw(g.frame)
+	g.localContent.Draw(g.frame)
+	display.Clear(screen)
+	var geoM ebiten.GeoM
+	geoM.Translate(g.effectsContent.ShakeOffset())
+	geoM.Concat(display.GeoM())
+	g.shaders.Apply(screen, g.frame, geoM, display.Filter())
+	g.overlay.Clear()
+	g.unshadedContent.Draw(g.overlay)
+	display.DrawFrame(screen, g.overlay)
+}
+
+// reuseImage allocates the image the first time, and after discardBuffers, and reuses it otherwise.
+func reuseImage(img *ebiten.Image) *ebiten.Image {
+	if img != nil {
+		return img
+	}
+	return ebiten.NewImage(common.GridDimPx, common.GridDimPx)
+}
+
+func (g *Game) discardBuffers() {
+	if g.frame != nil {
+		g.frame.Deallocate()
+		g.frame = nil
+	}
+	if g.overlay != nil {
+		g.overlay.Deallocate()
+		g.overlay = nil
+	}
+	g.shaders.DiscardBuffers()
+}
+
+func (g *Game) toggleBufferChurn() {
+	g.churnBuffers = !g.churnBuffers
+	variant := "reused buffers"
+	if g.churnBuffers {
+		variant = "churned buffers"
+	}
+	g.unshadedContent.SetDrawVariant(variant)
+	log.Info().Str("variant", variant).Msg("Draw buffers")
 }
 
-func (g *Game) applyShader(screen *ebiten.Image) {
-	w := screen.Bounds().Dx()
-	h := screen.Bounds().Dy()
-	opts := &ebiten.DrawRectShaderOptions{}
-	opts.Images[0] = screen
-	opts.Uniforms = map[string]interface{}{
-		// Kage uniforms here
+func (g *Game) toggleCellBatching() {
+	variant := "cells one by one"
+	if g.sharedContent.ToggleCellBatching() {
+		variant = "batched cells"
 	}
-	img := ebiten.NewImage(w, h)
-	img.DrawRectShader(w, h, g.shader, opts)
-	screen.DrawImage(img, nil)
+	g.unshadedContent.SetDrawVariant(variant)
+	log.Info().Str("variant", variant).Msg("Grid cells")
 }
@@ -0,0 +1,41 @@
+package effects
+
+import (
+	"image/color"
+	"snakehem/game/common"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+func (c *Content) Draw(screen *ebiten.Image) {
+	for _, p := range c.particles {
+		vector.FillRect(
+			screen,
+			p.x-p.size/2,
+			p.y-p.size/2,
+			p.size,
+			p.size,
+			fade(p.colour, float32(p.ttl)/particleTtl),
+			false,
+		)
+	}
+	if c.flashTtl > 0 {
+		vector.FillRect(
+			screen,
+			0,
+			0,
+			common.GridDimPx,
+			common.GridDimPx,
+			color.NRGBA{R: 255, G: 255, B: 255, A: uint8(flashAlpha * c.flashTtl / flashTtl)},
+			false,
+		)
+	}
+}
+
+// fade makes a colour transparent as its particle is dying out.
+func fade(colour color.Color, opacity float32) color.Color {
+	c := color.NRGBAModel.Convert(colour).(color.NRGBA)
+	c.A = uint8(float32(c.A) * min(1, opacity*2))
+	return c
+}
@@ -0,0 +1,125 @@
+package effects
+
+import (
+	"image/color"
+	"math"
+	"math/rand/v2"
+	"snakehem/game/common"
+	"snakehem/model"
+)
+
+const (
+	hitParticleCount       = 4
+	fragmentsPerLink       = 6
+	burstParticleCount     = 16
+	particleTtl            = model.Tps / 2
+	shakeTtl               = model.Tps / 3
+	maxShakePx             = 8
+	shakePxPerNippedLink   = 0.75
+	flashTtl               = model.Tps / 6
+	flashAlpha             = 60
+	particleFriction       = 0.92
+	particleMaxSpeedPx     = 3
+	particleSizePx         = 3
+	fragmentSizePx         = 4
+	maxParticles           = 2000
+	particleSpawnSpreadPx  = common.CellDimPx / 2
+	burstParticleMinSpeed  = 1
+	burstParticleSpeedSpan = 2
+)
+
+type particle struct {
+	x, y   float32
+	vx, vy float32
+	size   float32
+	colour color.Color
+	ttl    int
+}
+
+// Content is a layer of short-lived visual effects drawn over the shared content. Effects don't
+// affect the game in any way, so they're never sent over to anyone.
+type Content struct {
+	particles []particle
+	// shake is how far the screen is thrown at most, in frame pixels; it fades out over shakeTtl ticks
+	shake        float64
+	shakeTtl     int
+	flashTtl     int
+	reduceMotion bool
+}
+
+// NewContent creates the effects layer. With reduceMotion, there's neither screen shake nor flashes.
+func NewContent(reduceMotion bool) *Content {
+	return &Content{
+		particles:    nil,
+		shake:        0,
+		shakeTtl:     0,
+		flashTtl:     0,
+		reduceMotion: reduceMotion,
+	}
+}
+
+// Hit sprays a few particles from a link which has lost some health.
+func (c *Content) Hit(x, y int, colour color.Color) {
+	c.spawn(x, y, hitParticleCount, particleSizePx, 0, particleMaxSpeedPx, colour)
+}
+
+// Explode shatters the links of a nipped tail into fragments, and shakes the screen the more, the longer the tail.
+func (c *Content) Explode(cells [][2]int, colour color.Color) {
+	for _, cell := range cells {
+		c.spawn(cell[0], cell[1], fragmentsPerLink, fragmentSizePx, 0, particleMaxSpeedPx, colour)
+	}
+	c.Shake(math.Min(maxShakePx, shakePxPerNippedLink*float64(len(cells))))
+	c.Flash()
+}
+
+// Burst throws particles in all directions, like when an apple is eaten.
+func (c *Content) Burst(x, y int, colour color.Color) {
+	c.spawn(x, y, burstParticleCount, particleSizePx, burstParticleMinSpeed, burstParticleSpeedSpan, colour)
+}
+
+func (c *Content) Shake(px float64) {
+	if c.reduceMotion || px < c.currentShake() {
+		return
+	}
+	c.shake = px
+	c.shakeTtl = shakeTtl
+}
+
+func (c *Content) Flash() {
+	if c.reduceMotion {
+		return
+	}
+	c.flashTtl = flashTtl
+}
+
+// ShakeOffset tells how far the frame has to be moved this time, in frame pixels.
+func (c *Content) ShakeOffset() (float64, float64) {
+	s := c.currentShake()
+	if s == 0 {
+		return 0, 0
+	}
+	return math.Round((rand.Float64()*2 - 1) * s), math.Round((rand.Float64()*2 - 1) * s)
+}
+
+func (c *Content) currentShake() float64 {
+	return c.shake * float64(c.shakeTtl) / shakeTtl
+}
+
+// spawn adds particles around the centre of a cell, flying at random speeds between minSpeed and minSpeed+speedSpan.
+func (c *Content) spawn(x, y, count int, size float32, minSpeed, speedSpan float64, colour color.Color) {
+	cx := float32(x*common.CellDimPx) + common.CellDimPx/2
+	cy := float32(y*common.CellDimPx) + common.CellDimPx/2
+	for i := 0; i < count && len(c.particles) < maxParticles; i++ {
+		angle := rand.Float64() * 2 * math.Pi
+		speed := minSpeed + rand.Float64()*speedSpan
+		c.particles = append(c.particles, particle{
+			x:      cx + (rand.Float32()*2-1)*particleSpawnSpreadPx,
+			y:      cy + (rand.Float32()*2-1)*particleSpawnSpreadPx,
+			vx:     float32(math.Cos(angle) * speed),
+			vy:     float32(math.Sin(angle) * speed),
+			size:   size,
+			colour: colour,
+			ttl:    particleTtl/2 + rand.IntN(particleTtl/2),
+		})
+	}
+}
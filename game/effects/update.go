@@ -0,0 +1,23 @@
+package effects
+
+func (c *Content) Update() {
+	remaining := c.particles[:0]
+	for _, p := range c.particles {
+		p.ttl--
+		if p.ttl <= 0 {
+			continue
+		}
+		p.x += p.vx
+		p.y += p.vy
+		p.vx *= particleFriction
+		p.vy *= particleFriction
+		remaining = append(remaining, p)
+	}
+	c.particles = remaining
+	if c.shakeTtl > 0 {
+		c.shakeTtl--
+	}
+	if c.flashTtl > 0 {
+		c.flashTtl--
+	}
+}
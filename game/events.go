@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math"
+	"os"
+	"snakehem/assets/sound"
+	"snakehem/assets/theme"
+	"snakehem/game/shared"
+	"snakehem/game/shared/event"
+	"snakehem/input/haptics"
+	"snakehem/metrics"
+	"snakehem/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// subscribe hooks the haptics, sounds, effects and profiles up to the events of the action.
+func (g *Game) subscribe(eventsOut string) {
+	bus := g.sharedContent.Events
+	bus.SubscribeAll(func(tick uint64, e event.Event) {
+		log.Debug().Uint64("tick", tick).Str("type", e.Type()).Interface("event", e).Msg("Event")
+	})
+	if eventsOut != "" {
+		f, err := os.Create(eventsOut)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", eventsOut).Msg("Failed to create the event log")
+		}
+		event.WriteLog(bus, f)
+	}
+	if g.metrics != nil {
+		g.countEvents(bus)
+	}
+	event.Subscribe(bus, func(_ uint64, e event.DirectionChanged) {
+		g.sounds.Play(sound.Turn)
+	})
+	event.Subscribe(bus, func(_ uint64, e event.AppleEaten) {
+		g.rumble(e.SnakeId, haptics.AppleEaten)
+		g.sounds.Play(sound.Apple)
+		g.effectsContent.Burst(e.X, e.Y, theme.Current().Apple)
+	})
+	event.Subscribe(bus, func(_ uint64, e event.LinkBitten) {
+		g.rumble(e.TargetSnakeId, haptics.Bitten)
+		if e.SnakeId != e.TargetSnakeId {
+			g.rumble(e.SnakeId, haptics.Bite)
+		}
+		g.effectsContent.Hit(e.X, e.Y, g.sharedContent.Snakes[e.TargetSnakeId].Colour)
+		g.sounds.PlayVariant(sound.Bite, e.HealthPercent)
+	})
+	event.Subscribe(bus, func(_ uint64, e event.TailNipped) {
+		g.rumble(e.TargetSnakeId, haptics.TailLost)
+		g.effectsContent.Explode(e.Cells, g.sharedContent.Snakes[e.TargetSnakeId].Colour)
+		g.sounds.PlayVariant(sound.Nip, e.Length)
+	})
+	event.Subscribe(bus, func(_ uint64, e event.ScoreChanged) {
+		g.announceScore(e.Score, e.Score-e.Delta)
+	})
+	event.Subscribe(bus, func(_ uint64, e event.MatchEnded) {
+		if !e.Aborted {
+			g.recordMatch(e.Scores)
+			g.recordHeat(e.Scores)
+		}
+	})
+}
+
+// countEvents adds the gameplay to the counters of the metrics.
+func (g *Game) countEvents(bus *event.Bus) {
+	event.Subscribe(bus, func(_ uint64, e event.MatchEnded) {
+		if e.Aborted {
+			g.metrics.Inc(metrics.MatchesAborted)
+		} else {
+			g.metrics.Inc(metrics.MatchesPlayed)
+		}
+	})
+	event.Subscribe(bus, func(_ uint64, e event.LinkBitten) {
+		if e.SnakeId != e.TargetSnakeId {
+			g.metrics.Inc(metrics.Bites)
+		}
+	})
+	event.Subscribe(bus, func(_ uint64, e event.TailNipped) {
+		if e.SnakeId != e.TargetSnakeId {
+			g.metrics.Inc(metrics.TailsNipped)
+		}
+	})
+	event.Subscribe(bus, func(_ uint64, e event.AppleEaten) {
+		g.metrics.Inc(metrics.ApplesEaten)
+	})
+}
+
+// announceScore warns when a snake gets close to the target score, and plays the game over sound when it's reached.
+func (g *Game) announceScore(score, previousScore int) {
+	switch {
+	case score >= model.TargetScore && previousScore < model.TargetScore:
+		g.sounds.Play(sound.GameOver)
+	case shared.IsApproachingTarget(score) && !shared.IsApproachingTarget(previousScore):
+		g.sounds.Play(sound.Warning)
+	}
+}
+
+// recordMatch adds the results of a finished match to the profiles of the players.
+func (g *Game) recordMatch(scores []int) {
+	bestScore := 0
+	for _, score := range scores {
+		bestScore = max(bestScore, score)
+	}
+	names := make([]string, len(g.sharedContent.Snakes))
+	for _, snake := range g.sharedContent.Snakes {
+		names[snake.Id] = snake.Name
+		g.profiles.RecordMatch(snake.Name, scores[snake.Id], scores[snake.Id] == bestScore)
+		s := g.sharedContent.Stats.Of(snake.Id)
+		for _, opponent := range g.sharedContent.Snakes {
+			if opponent != snake {
+				g.profiles.RecordRivalry(snake.Name, opponent.Name, s.BitesOn[opponent.Id], s.NipsOn[opponent.Id])
+			}
+		}
+	}
+	g.profiles.RecordRatings(names, scores)
+	for _, snake := range g.sharedContent.Snakes {
+		if p := g.profiles.Get(snake.Name); p != nil {
+			snake.Rating = int(math.Round(p.SkillRating()))
+		}
+	}
+	g.profiles.Save()
+}
+
+// recordHeat adds the results of a finished match to the tournament, if it was a heat of one.
+func (g *Game) recordHeat(scores []int) {
+	if g.sharedContent.CurrentHeat() == nil {
+		return
+	}
+	byName := make(map[string]int, len(g.sharedContent.Snakes))
+	for _, snake := range g.sharedContent.Snakes {
+		byName[snake.Name] = scores[snake.Id]
+	}
+	g.sharedContent.Tournament.RecordHeat(byName)
+}
+
+// allTimeNemesis looks the nemesis up in the profile of a player, if there's one.
+func (g *Game) allTimeNemesis(name string) (string, bool) {
+	p := g.profiles.Get(name)
+	if p == nil {
+		return "", false
+	}
+	return p.Nemesis()
+}
@@ -2,12 +2,21 @@ package game
 
 import (
 	"snakehem/assets/shader"
-	"snakehem/game/common"
+	"snakehem/assets/sound"
+	"snakehem/assets/theme"
+	"snakehem/diagnostics"
+	"snakehem/display"
+	"snakehem/game/effects"
 	"snakehem/game/local"
 	"snakehem/game/shared"
 	"snakehem/game/unshaded"
 	"snakehem/input/controller"
+	"snakehem/input/haptics"
+	"snakehem/metrics"
 	"snakehem/model"
+	"snakehem/profile"
+	"snakehem/rating"
+	"snakehem/tournament"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/pbnjay/pixfont"
@@ -17,34 +26,165 @@ import (
 type Game struct {
 	sharedContent     *shared.Content
 	localContent      *local.Content
+	effectsContent    *effects.Content
 	unshadedContent   *unshaded.Content
 	controllers       []controller.Controller
 	activeControllers []controller.Controller
-	shader            *ebiten.Shader
+	// exitHeldTicks are how long the exit buttons of the players were held on the previous tick, indexed the same way
+	exitHeldTicks []int
+	haptics       *haptics.Haptics
+	profiles      *profile.Store
+	shaders       *shader.Pipeline
+	sounds        *sound.Mixer
+	// metrics is nil unless the metrics are served, see Config.MetricsAddr
+	metrics *metrics.Exporter
+	// frame and overlay are reused from one draw to another, see doDraw
+	frame   *ebiten.Image
+	overlay *ebiten.Image
+	// churnBuffers brings back allocating the offscreen images on every draw, to compare the draw times
+	churnBuffers bool
 }
 
-func Run() {
+type Config struct {
+	Rumble       bool
+	ProfilesPath string
+	Fullscreen   bool
+	// WindowWidth and WindowHeight are zero to fit the window to the monitor
+	WindowWidth  int
+	WindowHeight int
+	// Shader is a preset name or a comma-separated list of effects
+	Shader       string
+	ShaderParams map[string]float64
+	// ShaderDir is where extra effects are loaded from, empty for none
+	ShaderDir string
+	// Smooth makes the game draw every tick instead of every TpsMultiplier ticks
+	Smooth bool
+	// ReduceMotion turns off screen shake and flashes
+	ReduceMotion bool
+	Theme        string
+	// ThemeDir is where extra themes are loaded from, empty for none
+	ThemeDir string
+	// ColourBlind is the name of a colour vision deficiency to adapt to, "none" by default
+	ColourBlind string
+	Mute        bool
+	// EffectsVolume and MusicVolume are from 0 to 1
+	EffectsVolume float64
+	MusicVolume   float64
+	// EventsOut is a file every event of the action is written to as JSON lines, empty for none
+	EventsOut string
+	// TournamentRoster is a file listing the players of a tournament, empty outside the tournament mode
+	TournamentRoster string
+	// TournamentPath is where the progress of the tournament is saved, and resumed from
+	TournamentPath string
+	HeatSize       int
+	// Advance is how many players go on from each heat
+	Advance int
+	// MetricsAddr is the address to serve the metrics on in the OpenMetrics format, empty for none
+	MetricsAddr string
+	// PerfDir is where the performance dumps and captures are written to
+	PerfDir string
+	// PprofAddr is the address to serve net/http/pprof on, empty for none
+	PprofAddr string
+}
+
+func Run(cfg Config) {
 	pixfont.Spacing = 0
-	// debug doesn't work well in fullscreen mode
-	//ebiten.SetWindowSize(960, 960)
-	ebiten.SetFullscreen(true)
+	if cfg.WindowWidth > 0 && cfg.WindowHeight > 0 {
+		ebiten.SetWindowSize(cfg.WindowWidth, cfg.WindowHeight)
+	} else {
+		ebiten.SetWindowSize(display.DefaultWindowSize())
+	}
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetFullscreen(cfg.Fullscreen)
 	ebiten.SetTPS(model.Tps)
 	ebiten.SetWindowTitle("snakehem")
-	ebiten.SetCursorMode(ebiten.CursorModeHidden)
 	ebiten.SetScreenClearedEveryFrame(false)
+	if cfg.ThemeDir != "" {
+		theme.LoadDir(cfg.ThemeDir)
+	}
+	if err := theme.SwitchTo(cfg.Theme); err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up the theme")
+	}
+	deficiency, err := theme.ParseDeficiency(cfg.ColourBlind)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up the colour-blind mode")
+	}
+	theme.SetDeficiency(deficiency)
+	shaders, err := shader.NewPipeline(cfg.Shader, cfg.ShaderParams, cfg.ShaderDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up shaders")
+	}
+	var exporter *metrics.Exporter
+	if cfg.MetricsAddr != "" {
+		exporter, err = metrics.Serve(cfg.MetricsAddr)
+		if err != nil {
+			log.Fatal().Err(err).Str("addr", cfg.MetricsAddr).Msg("Failed to serve the metrics")
+		}
+	}
+	if cfg.PprofAddr != "" {
+		if err := diagnostics.ServePprof(cfg.PprofAddr); err != nil {
+			log.Fatal().Err(err).Str("addr", cfg.PprofAddr).Msg("Failed to serve pprof")
+		}
+	}
+	sharedContent := shared.NewContent()
+	sharedContent.Smooth = cfg.Smooth
 	g := &Game{
-		sharedContent:     shared.NewContent(),
+		sharedContent:     sharedContent,
 		localContent:      local.NewContent(),
-		unshadedContent:   unshaded.NewContent(),
+		effectsContent:    effects.NewContent(cfg.ReduceMotion),
+		unshadedContent:   unshaded.NewContent(exporter, cfg.PerfDir),
 		controllers:       nil,
 		activeControllers: nil,
-		shader:            shader.NewShader(),
+		exitHeldTicks:     nil,
+		haptics:           haptics.NewHaptics(cfg.Rumble),
+		profiles:          profile.Load(cfg.ProfilesPath),
+		shaders:           shaders,
+		sounds:            sound.NewMixer(cfg.Mute, cfg.EffectsVolume, cfg.MusicVolume),
+		metrics:           exporter,
+		frame:             nil,
+		overlay:           nil,
+		churnBuffers:      false,
+	}
+	g.subscribe(cfg.EventsOut)
+	if cfg.TournamentRoster != "" {
+		g.startTournament(cfg)
 	}
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal().Err(err).Send()
 	}
 }
 
-func (g *Game) Layout(_, _ int) (screenWidth, screenHeight int) {
-	return common.GridDimPx, common.GridDimPx
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return display.Layout(outsideWidth, outsideHeight)
+}
+
+// startTournament resumes the tournament saved at TournamentPath, unless it's over already,
+// in which case a new one is drawn from the roster, seeded by the skill ratings of the players.
+func (g *Game) startTournament(cfg Config) {
+	t, err := tournament.Load(cfg.TournamentPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load the tournament")
+	}
+	if t != nil && !t.IsOver() {
+		log.Info().Str("path", cfg.TournamentPath).Int("round", len(t.Rounds)).Msg("Tournament resumed")
+	} else {
+		roster, err := tournament.ReadRoster(cfg.TournamentRoster)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", cfg.TournamentRoster).Msg("Failed to read the tournament roster")
+		}
+		ratings := make([]float64, len(roster))
+		for i, name := range roster {
+			ratings[i] = rating.Initial
+			if p := g.profiles.Get(name); p != nil {
+				ratings[i] = p.SkillRating()
+			}
+		}
+		t, err = tournament.New(roster, ratings, cfg.HeatSize, cfg.Advance, cfg.TournamentPath)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to set up the tournament")
+		}
+		log.Info().Str("path", cfg.TournamentPath).Int("players", len(roster)).Msg("Tournament started")
+	}
+	g.sharedContent.Tournament = t
+	g.sharedContent.Stage = shared.Bracket
 }
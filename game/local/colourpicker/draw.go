@@ -0,0 +1,145 @@
+package colourpicker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"snakehem/assets/theme"
+	"snakehem/game/common"
+	"snakehem/input/pointer"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/colornames"
+)
+
+const (
+	previewY      = common.GridDimPx / 4.5
+	swatchesY     = common.GridDimPx * 2 / 5
+	swatchSizePx  = 44
+	swatchGapPx   = 16
+	frameWidthPx  = 3
+	slidersY      = common.GridDimPx * 3 / 5
+	sliderWidthPx = 256 + 2
+)
+
+// sliderRowPx keeps the labels of the sliders apart with a larger text font.
+func sliderRowPx() int {
+	return common.TextLineHeight() * 3 / 2
+}
+
+var channelNames = [3]string{"R", "G", "B"}
+
+func (p *ColourPicker) Draw(screen *ebiten.Image) {
+	t := theme.Current()
+	screen.Fill(t.Background)
+
+	common.DrawTextCentered(screen, "PICK YOUR COLOUR", t.Text, common.GridDimPx/8.0, t.ScoreFont)
+	selected := p.Selected()
+	common.DrawTextCentered(screen, p.label, selected, previewY, t.ScoreFont)
+
+	hovered := -1
+	if x, y, ok := pointer.Hover(); ok && !p.editing {
+		if i, found := swatchAt(x, y, p.swatchCount()); found {
+			hovered = i
+		}
+	}
+	for i := 0; i < p.swatchCount(); i++ {
+		bounds := swatchBounds(i, p.swatchCount())
+		if i == p.cursor || i == hovered {
+			frame := bounds.Inset(-frameWidthPx)
+			vector.FillRect(screen, float32(frame.Min.X), float32(frame.Min.Y), float32(frame.Dx()), float32(frame.Dy()), t.Highlight, false)
+		}
+		var colour color.Color
+		if p.isCustomSwatch(i) {
+			colour = p.customColour()
+		} else {
+			colour = p.colours[i]
+		}
+		vector.FillRect(screen, float32(bounds.Min.X), float32(bounds.Min.Y), swatchSizePx, swatchSizePx, colour, false)
+		if p.isCustomSwatch(i) {
+			txt := "RGB"
+			t.TextFont.DrawString(
+				screen,
+				bounds.Min.X+(swatchSizePx-t.TextFont.MeasureString(txt))/2,
+				bounds.Min.Y+(swatchSizePx-t.TextFont.GetHeight())/2,
+				txt,
+				common.ContrastingColour(colour),
+			)
+		}
+	}
+
+	if p.editing {
+		p.drawSliders(screen)
+	}
+	messageY := float64(swatchesY + swatchSizePx + common.TextLineHeight())
+	if p.error != nil {
+		common.DrawTextCentered(screen, strings.ToUpper(p.error.Error()), colornames.Orangered, messageY, t.TextFont)
+	} else if theme.Contrast(selected, t.Background) < theme.MinContrast {
+		common.DrawTextCentered(screen, "HARD TO SEE ON THIS BACKGROUND", t.Text, messageY, t.TextFont)
+	}
+
+	instructionsY := common.GridDimPx - float64(common.TextLineHeight())*2.5
+	if p.editing {
+		common.DrawTextCentered(screen, "UP/DOWN: CHANNEL  LEFT/RIGHT: ADJUST", t.Text, instructionsY, t.TextFont)
+		common.DrawTextCentered(screen, "START: CONFIRM  SELECT: BACK", t.Text, instructionsY+float64(common.TextLineHeight()), t.TextFont)
+	} else {
+		common.DrawTextCentered(screen, "LEFT/RIGHT: CHOOSE COLOUR", t.Text, instructionsY, t.TextFont)
+		common.DrawTextCentered(screen, "START: CONFIRM", t.Text, instructionsY+float64(common.TextLineHeight()), t.TextFont)
+	}
+}
+
+func (p *ColourPicker) drawSliders(screen *ebiten.Image) {
+	t := theme.Current()
+	for channel, value := range p.custom {
+		bounds := sliderBounds(channel)
+		colour := t.Info
+		if channel == p.channel {
+			colour = t.Highlight
+		}
+		label := fmt.Sprintf("%s %3d", channelNames[channel], value)
+		t.TextFont.DrawString(screen, bounds.Min.X-t.TextFont.MeasureString(label)-swatchGapPx, bounds.Min.Y, label, colour)
+		vector.StrokeRect(screen, float32(bounds.Min.X), float32(bounds.Min.Y), float32(bounds.Dx()), float32(bounds.Dy()), 1, colour, false)
+		vector.FillRect(screen, float32(bounds.Min.X+1), float32(bounds.Min.Y+1), float32(value), float32(bounds.Dy()-2), colour, false)
+	}
+	common.DrawTextCentered(screen, okTxt, t.Info, float64(okBounds().Min.Y), t.ScoreFont)
+}
+
+const okTxt = "OK"
+
+// okBounds is the row with the OK button, under the sliders.
+func okBounds() image.Rectangle {
+	return common.TextCenteredBounds(okTxt, float64(slidersY+len(channelNames)*sliderRowPx()), theme.Current().ScoreFont)
+}
+
+func swatchBounds(i, count int) image.Rectangle {
+	totalWidth := count*swatchSizePx + (count-1)*swatchGapPx
+	x := (common.GridDimPx-totalWidth)/2 + i*(swatchSizePx+swatchGapPx)
+	return image.Rect(x, swatchesY, x+swatchSizePx, swatchesY+swatchSizePx)
+}
+
+func swatchAt(x, y, count int) (int, bool) {
+	for i := 0; i < count; i++ {
+		if (image.Point{X: x, Y: y}).In(swatchBounds(i, count)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func sliderBounds(channel int) image.Rectangle {
+	x := (common.GridDimPx - sliderWidthPx) / 2
+	y := slidersY + channel*sliderRowPx()
+	return image.Rect(x, y, x+sliderWidthPx, y+common.TextLineHeight())
+}
+
+// sliderAt finds the channel slider at a given position, and the value it stands for.
+func sliderAt(x, y int) (int, uint8, bool) {
+	for channel := range channelNames {
+		if b := sliderBounds(channel); (image.Point{X: x, Y: y}).In(b) {
+			return channel, uint8(min(255, max(0, x-b.Min.X-1))), true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,104 @@
+package colourpicker
+
+import (
+	"image/color"
+	"snakehem/game/common"
+	"snakehem/input/controller"
+)
+
+// channelStep is how much a custom colour channel changes with one press of left or right.
+const channelStep = 5
+
+// ColourPicker lets a joining player choose one of the colours nobody has taken yet,
+// or mix a custom one. The last swatch always stands for the custom colour.
+type ColourPicker struct {
+	label      string
+	colours    []color.Color
+	cursor     int
+	editing    bool
+	channel    int
+	custom     [3]uint8
+	controller controller.Controller
+	callback   func(color.Color)
+	validation func(color.Color) error
+	error      error
+}
+
+func NewColourPicker(controller controller.Controller) *ColourPicker {
+	return &ColourPicker{
+		label:      "",
+		colours:    nil,
+		cursor:     0,
+		editing:    false,
+		channel:    0,
+		custom:     [3]uint8{128, 128, 128},
+		controller: controller,
+		callback:   func(color.Color) {},
+		validation: func(color.Color) error { return nil },
+		error:      nil,
+	}
+}
+
+func (p *ColourPicker) WithLabel(label string) *ColourPicker {
+	p.label = label
+	return p
+}
+
+func (p *ColourPicker) WithColours(colours []color.Color) *ColourPicker {
+	p.colours = colours
+	p.cursor = 0
+	return p
+}
+
+// WithSelected moves the cursor to a given colour, or makes it the custom one if it's not among the swatches.
+func (p *ColourPicker) WithSelected(colour color.Color) *ColourPicker {
+	for i, c := range p.colours {
+		if common.SameColour(c, colour) {
+			p.cursor = i
+			return p
+		}
+	}
+	c := color.NRGBAModel.Convert(colour).(color.NRGBA)
+	p.custom = [3]uint8{c.R, c.G, c.B}
+	p.cursor = len(p.colours)
+	return p
+}
+
+func (p *ColourPicker) WithCallback(callback func(color.Color)) *ColourPicker {
+	p.callback = callback
+	return p
+}
+
+// WithValidation keeps the picker open with the error shown while the colour isn't accepted,
+// which only a custom one can fail, as the swatches are left to choose from.
+func (p *ColourPicker) WithValidation(validation func(color.Color) error) *ColourPicker {
+	p.validation = validation
+	return p
+}
+
+func (p *ColourPicker) swatchCount() int {
+	return len(p.colours) + 1
+}
+
+func (p *ColourPicker) isCustomSwatch(i int) bool {
+	return i == len(p.colours)
+}
+
+func (p *ColourPicker) customColour() color.Color {
+	return color.NRGBA{R: p.custom[0], G: p.custom[1], B: p.custom[2], A: 255}
+}
+
+// Selected is the colour under the cursor, which is previewed.
+func (p *ColourPicker) Selected() color.Color {
+	if p.isCustomSwatch(p.cursor) {
+		return p.customColour()
+	}
+	return p.colours[p.cursor]
+}
+
+func (p *ColourPicker) Submit() {
+	p.error = p.validation(p.Selected())
+	if p.error == nil {
+		p.callback(p.Selected())
+	}
+}
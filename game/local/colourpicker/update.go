@@ -0,0 +1,73 @@
+package colourpicker
+
+import (
+	"image"
+	"snakehem/game/common"
+	"snakehem/input/pointer"
+)
+
+func (p *ColourPicker) Update() {
+	selected := p.Selected()
+	defer func() {
+		// the error is about the colour it was shown for
+		if !common.SameColour(selected, p.Selected()) {
+			p.error = nil
+		}
+	}()
+	if p.editing {
+		p.updateCustom()
+		return
+	}
+	c := p.controller
+	for _, pos := range pointer.AppendJustClicked(nil) {
+		if i, found := swatchAt(pos.X, pos.Y, p.swatchCount()); found {
+			p.cursor = i
+			p.confirm()
+			return
+		}
+	}
+	if c.IsLeftPressed() {
+		p.cursor = (p.cursor + p.swatchCount() - 1) % p.swatchCount()
+	} else if c.IsRightPressed() {
+		p.cursor = (p.cursor + 1) % p.swatchCount()
+	} else if c.IsStartJustPressed() {
+		p.confirm()
+	}
+}
+
+// confirm submits the colour under the cursor, or starts mixing a custom one.
+func (p *ColourPicker) confirm() {
+	if p.isCustomSwatch(p.cursor) {
+		p.editing = true
+		p.channel = 0
+		return
+	}
+	p.Submit()
+}
+
+func (p *ColourPicker) updateCustom() {
+	c := p.controller
+	for _, pos := range pointer.AppendJustClicked(nil) {
+		if channel, value, found := sliderAt(pos.X, pos.Y); found {
+			p.channel = channel
+			p.custom[channel] = value
+		} else if (image.Point{X: pos.X, Y: pos.Y}).In(okBounds()) {
+			p.Submit()
+			return
+		}
+	}
+	switch {
+	case c.IsExitJustPressed():
+		p.editing = false
+	case c.IsUpPressed():
+		p.channel = (p.channel + len(p.custom) - 1) % len(p.custom)
+	case c.IsDownPressed():
+		p.channel = (p.channel + 1) % len(p.custom)
+	case c.IsLeftPressed():
+		p.custom[p.channel] = uint8(max(0, int(p.custom[p.channel])-channelStep))
+	case c.IsRightPressed():
+		p.custom[p.channel] = uint8(min(255, int(p.custom[p.channel])+channelStep))
+	case c.IsStartJustPressed():
+		p.Submit()
+	}
+}
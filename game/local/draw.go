@@ -7,5 +7,9 @@ import (
 func (c *Content) Draw(screen *ebiten.Image) {
 	if c.textInput != nil {
 		c.textInput.Draw(screen)
+	} else if c.profilePicker != nil {
+		c.profilePicker.Draw(screen)
+	} else if c.colourPicker != nil {
+		c.colourPicker.Draw(screen)
 	}
 }
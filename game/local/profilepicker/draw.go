@@ -0,0 +1,108 @@
+package profilepicker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"snakehem/assets/theme"
+	"snakehem/game/common"
+	"snakehem/input/pointer"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	listStartY   = common.GridDimPx / 3.5
+	newPlayerTxt = "NEW PLAYER"
+)
+
+// rowHeight is one and a half times the height of the font the names are drawn with.
+func rowHeight() int {
+	return common.ScoreHeight() * 3 / 2
+}
+
+func (p *ProfilePicker) Draw(screen *ebiten.Image) {
+	screen.Fill(theme.Current().Background)
+
+	common.DrawTextCentered(
+		screen,
+		p.label,
+		theme.Current().Text,
+		common.GridDimPx/8.0,
+		theme.Current().ScoreFont,
+	)
+
+	hoveredRow := -1
+	if x, y, ok := pointer.Hover(); ok {
+		if row, found := p.rowAt(x, y); found {
+			hoveredRow = row
+		}
+	}
+
+	for i := 0; i < VisibleRows; i++ {
+		row := p.scrollRow + i
+		if row >= p.rowCount() {
+			break
+		}
+		txt := p.rowLabel(row)
+		var colour color.Color
+		if p.isNewPlayerRow(row) {
+			colour = theme.Current().Text
+		} else {
+			colour = p.options[row].Colour
+		}
+		if row == p.cursorRow {
+			txt = "[" + txt + "]"
+			colour = theme.Current().Highlight
+		} else if row == hoveredRow {
+			colour = theme.Current().Highlight
+		}
+		common.DrawTextCentered(screen, txt, colour, listStartY+float64(i*rowHeight()), theme.Current().ScoreFont)
+	}
+
+	if !p.isNewPlayerRow(p.cursorRow) {
+		stats := p.options[p.cursorRow].Profile.Stats
+		common.DrawTextCentered(
+			screen,
+			fmt.Sprintf("MATCHES: %d  WINS: %d  BEST: %d", stats.MatchesPlayed, stats.Wins, stats.BestScore),
+			theme.Current().Info,
+			listStartY+float64(VisibleRows*rowHeight()+common.TextLineHeight()),
+			theme.Current().TextFont,
+		)
+	}
+
+	instructionsY := common.GridDimPx - float64(common.TextLineHeight())*2.5
+	common.DrawTextCentered(screen, "UP/DOWN: CHOOSE PLAYER", theme.Current().Text, instructionsY, theme.Current().TextFont)
+	common.DrawTextCentered(screen, "START: CONFIRM", theme.Current().Text, instructionsY+float64(common.TextLineHeight()), theme.Current().TextFont)
+}
+
+// rowAt finds the visible row whose name covers a given position of the screen.
+func (p *ProfilePicker) rowAt(x, y int) (int, bool) {
+	for i := 0; i < VisibleRows; i++ {
+		row := p.scrollRow + i
+		if row >= p.rowCount() {
+			break
+		}
+		if (image.Point{X: x, Y: y}).In(p.rowBounds(row)) {
+			return row, true
+		}
+	}
+	return 0, false
+}
+
+// rowBounds spans the name of a visible row, and the gaps above and below it.
+func (p *ProfilePicker) rowBounds(row int) image.Rectangle {
+	top := listStartY + float64((row-p.scrollRow)*rowHeight())
+	bounds := common.TextCenteredBounds(p.rowLabel(row), top, theme.Current().ScoreFont)
+	gap := (rowHeight() - common.ScoreHeight()) / 2
+	bounds.Min.Y -= gap
+	bounds.Max.Y = bounds.Min.Y + rowHeight()
+	return bounds
+}
+
+func (p *ProfilePicker) rowLabel(row int) string {
+	if p.isNewPlayerRow(row) {
+		return newPlayerTxt
+	}
+	return p.options[row].Profile.Name
+}
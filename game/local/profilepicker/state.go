@@ -0,0 +1,70 @@
+package profilepicker
+
+import (
+	"image/color"
+	"snakehem/input/controller"
+	"snakehem/profile"
+)
+
+const VisibleRows = 9
+
+type Option struct {
+	Profile *profile.Profile
+	Colour  color.Color
+}
+
+// ProfilePicker lets a joining player pick one of the stored profiles
+// or ask for a new one. The last row always stands for a new player.
+type ProfilePicker struct {
+	label      string
+	options    []Option
+	cursorRow  int
+	scrollRow  int
+	controller controller.Controller
+	callback   func(p *profile.Profile)
+}
+
+func NewProfilePicker(controller controller.Controller) *ProfilePicker {
+	return &ProfilePicker{
+		label:      "",
+		options:    nil,
+		cursorRow:  0,
+		scrollRow:  0,
+		controller: controller,
+		callback:   func(*profile.Profile) {},
+	}
+}
+
+func (p *ProfilePicker) WithLabel(label string) *ProfilePicker {
+	p.label = label
+	return p
+}
+
+func (p *ProfilePicker) WithOptions(options []Option) *ProfilePicker {
+	p.options = options
+	p.cursorRow = 0
+	p.scrollRow = 0
+	return p
+}
+
+// WithCallback sets a function called with the picked profile, or nil when a new player is asked for.
+func (p *ProfilePicker) WithCallback(callback func(p *profile.Profile)) *ProfilePicker {
+	p.callback = callback
+	return p
+}
+
+func (p *ProfilePicker) rowCount() int {
+	return len(p.options) + 1
+}
+
+func (p *ProfilePicker) isNewPlayerRow(row int) bool {
+	return row == len(p.options)
+}
+
+func (p *ProfilePicker) Submit() {
+	if p.isNewPlayerRow(p.cursorRow) {
+		p.callback(nil)
+	} else {
+		p.callback(p.options[p.cursorRow].Profile)
+	}
+}
@@ -0,0 +1,29 @@
+package profilepicker
+
+import (
+	"snakehem/input/pointer"
+)
+
+func (p *ProfilePicker) Update() {
+	c := p.controller
+	for _, pos := range pointer.AppendJustClicked(nil) {
+		if row, found := p.rowAt(pos.X, pos.Y); found {
+			p.cursorRow = row
+			p.Submit()
+			return
+		}
+	}
+	if c.IsUpPressed() {
+		p.cursorRow = (p.cursorRow + p.rowCount() - 1) % p.rowCount()
+	} else if c.IsDownPressed() {
+		p.cursorRow = (p.cursorRow + 1) % p.rowCount()
+	} else if c.IsStartJustPressed() {
+		p.Submit()
+		return
+	}
+	if p.cursorRow < p.scrollRow {
+		p.scrollRow = p.cursorRow
+	} else if p.cursorRow >= p.scrollRow+VisibleRows {
+		p.scrollRow = p.cursorRow - VisibleRows + 1
+	}
+}
@@ -2,20 +2,27 @@ package local
 
 import (
 	"image/color"
+	"snakehem/game/local/colourpicker"
+	"snakehem/game/local/profilepicker"
 	"snakehem/game/local/textinput"
 	"snakehem/input/controller"
 	"snakehem/model"
+	"snakehem/profile"
 )
 
 type Content struct {
-	stage     Stage
-	textInput *textinput.TextInput
+	stage         Stage
+	textInput     *textinput.TextInput
+	profilePicker *profilepicker.ProfilePicker
+	colourPicker  *colourpicker.ColourPicker
 }
 
 func NewContent() *Content {
 	return &Content{
-		stage:     Off,
-		textInput: nil,
+		stage:         Off,
+		textInput:     nil,
+		profilePicker: nil,
+		colourPicker:  nil,
 	}
 }
 
@@ -28,9 +35,56 @@ type Stage uint8
 const (
 	Off Stage = iota
 	PlayerName
+	PlayerProfile
+	PlayerColour
 )
 
-func (c *Content) SwitchToPlayerNameStage(ctrl controller.Controller, playerName string, colour color.Color, cb func(string)) {
+// SwitchToPlayerProfileStage offers a joining player to pick one of the stored profiles, falling back
+// to the player name entry when there are none or when a new player is asked for. The callback receives
+// the picked profile, or nil with the entered name, which has passed the validation.
+func (c *Content) SwitchToPlayerProfileStage(
+	ctrl controller.Controller,
+	options []profilepicker.Option,
+	playerName string,
+	colour color.Color,
+	validation func(string) error,
+	cb func(p *profile.Profile, name string),
+) {
+	if c.textInput != nil || c.profilePicker != nil || c.colourPicker != nil {
+		return
+	}
+	newPlayer := func() {
+		c.SwitchToPlayerNameStage(ctrl, playerName, colour, validation, func(name string) {
+			cb(nil, name)
+		})
+	}
+	if len(options) == 0 {
+		newPlayer()
+		return
+	}
+	c.stage = PlayerProfile
+	c.profilePicker = profilepicker.
+		NewProfilePicker(ctrl).
+		WithLabel("WHO IS PLAYING?").
+		WithOptions(options).
+		WithCallback(func(p *profile.Profile) {
+			c.stage = Off
+			c.profilePicker = nil
+			if p == nil {
+				newPlayer()
+			} else {
+				cb(p, p.Name)
+			}
+		})
+}
+
+func (c *Content) SwitchToPlayerNameStage(
+	ctrl controller.Controller,
+	playerName string,
+	colour color.Color,
+	validation func(string) error,
+	cb func(string),
+) {
 	if c.textInput != nil {
 		return
 	}
@@ -44,9 +98,38 @@ func (c *Content) SwitchToPlayerNameStage(ctrl controller.Controller, playerName
 		WithKeyboardCols(12).
 		WithCapsBehaviour(textinput.CapsBehaviourNames).
 		ValidateNotEmpty("name cannot be empty").
+		ValidateWith(validation).
 		WithCallback(func(name string) {
-			cb(name)
+			// cleared first, so that the callback can go on to another stage
 			c.stage = Off
 			c.textInput = nil
+			cb(name)
+		})
+}
+
+// SwitchToPlayerColourStage lets a joining player choose among the colours nobody has taken yet,
+// or mix a custom one, which the validation gets to turn down.
+func (c *Content) SwitchToPlayerColourStage(
+	ctrl controller.Controller,
+	playerName string,
+	colours []color.Color,
+	selected color.Color,
+	validation func(color.Color) error,
+	cb func(color.Color),
+) {
+	if c.colourPicker != nil {
+		return
+	}
+	c.stage = PlayerColour
+	c.colourPicker = colourpicker.
+		NewColourPicker(ctrl).
+		WithLabel(playerName).
+		WithColours(colours).
+		WithSelected(selected).
+		WithValidation(validation).
+		WithCallback(func(colour color.Color) {
+			c.stage = Off
+			c.colourPicker = nil
+			cb(colour)
 		})
 }
@@ -2,9 +2,10 @@ package textinput
 
 import (
 	"image/color"
-	"snakehem/assets/pxterm16"
-	"snakehem/assets/pxterm24"
+	"snakehem/assets/theme"
 	"snakehem/game/common"
+	"snakehem/input/pointer"
+	"snakehem/input/touch"
 	"snakehem/util"
 	"strings"
 
@@ -12,27 +13,38 @@ import (
 	"golang.org/x/image/colornames"
 )
 
+const currentNameY = common.GridDimPx / 2.7
+
+// keyboardStartY and keySpacingY follow the height of the font of the keys, which comes with the theme.
+func keyboardStartY() int {
+	return int(currentNameY + float64(common.ScoreHeight()*3))
+}
+
+// keySpacingY is the vertical spacing between rows
+func keySpacingY() int {
+	return common.ScoreHeight() * 4 / 3
+}
+
 func (t *TextInput) Draw(screen *ebiten.Image) {
-	screen.Fill(colornames.Darkolivegreen)
+	screen.Fill(theme.Current().Background)
 
 	// Draw title
 	titleY := common.GridDimPx / 4.0
 	common.DrawTextCentered(
 		screen,
 		t.label,
-		colornames.Yellow,
+		theme.Current().Text,
 		titleY,
-		pxterm24.Font,
+		theme.Current().ScoreFont,
 	)
 
 	// Draw current name being entered
-	currentNameY := common.GridDimPx / 2.7
 	common.DrawTextCentered(
 		screen,
 		"["+util.PadRight(t.value, t.maxLength)+"]",
 		t.textColour,
 		currentNameY,
-		pxterm24.Font,
+		theme.Current().ScoreFont,
 	)
 	if t.cursorShown {
 		common.DrawTextCentered(
@@ -40,7 +52,7 @@ func (t *TextInput) Draw(screen *ebiten.Image) {
 			util.PadRight("", len(t.value)+1)+"█"+util.PadRight("", t.maxLength-len(t.value)),
 			colornames.Orange,
 			currentNameY,
-			pxterm24.Font,
+			theme.Current().ScoreFont,
 		)
 	}
 	common.DrawTextCentered(
@@ -48,32 +60,34 @@ func (t *TextInput) Draw(screen *ebiten.Image) {
 		"["+util.PadRight("", t.maxLength)+"]",
 		colornames.Orange,
 		currentNameY,
-		pxterm24.Font,
+		theme.Current().ScoreFont,
 	)
 
 	// Draw virtual keyboard grid
-	keyboardStartY := currentNameY + float64(common.Pxterm24Height*3)
-	t.drawKeyboardGrid(screen, keyboardStartY)
+	t.drawKeyboardGrid(screen)
 
 	// Draw error if needed
 	if t.error != nil {
-		errorY := titleY + float64(common.Pxterm24Height+common.Pxterm16Height/2)
-		common.DrawTextCentered(screen, strings.ToUpper(t.error.Error()), colornames.Orangered, errorY, pxterm16.Font)
+		errorY := titleY + float64(common.ScoreHeight()+common.TextLineHeight()/2)
+		common.DrawTextCentered(screen, strings.ToUpper(t.error.Error()), colornames.Orangered, errorY, theme.Current().TextFont)
 	}
 
 	// Draw instructions
-	instructionsY := common.GridDimPx - float64(common.Pxterm16Height)*2.5
-	common.DrawTextCentered(screen, "ARROWS: NAVIGATE KEYBOARD", colornames.Yellow, instructionsY, pxterm16.Font)
-	common.DrawTextCentered(screen, "START: PRESS SELECTED KEY", colornames.Yellow, instructionsY+float64(common.Pxterm16Height), pxterm16.Font)
+	instructionsY := common.GridDimPx - float64(common.TextLineHeight())*2.5
+	if _, ok := t.controller.(touch.Touch); ok {
+		common.DrawTextCentered(screen, "SWIPE: NAVIGATE KEYBOARD", theme.Current().Text, instructionsY, theme.Current().TextFont)
+		common.DrawTextCentered(screen, "TAP: PRESS KEY", theme.Current().Text, instructionsY+float64(common.TextLineHeight()), theme.Current().TextFont)
+	} else {
+		common.DrawTextCentered(screen, "ARROWS: NAVIGATE KEYBOARD", theme.Current().Text, instructionsY, theme.Current().TextFont)
+		common.DrawTextCentered(screen, "START: PRESS SELECTED KEY", theme.Current().Text, instructionsY+float64(common.TextLineHeight()), theme.Current().TextFont)
+	}
 }
 
-func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
-	var keySpacingX = common.GridDimPx / t.keyboardCols // Horizontal spacing between keys
-	const keySpacingY = 32                              // Vertical spacing between rows
-
-	// Calculate grid dimensions
-	totalGridWidth := (t.keyboardCols - 1) * keySpacingX
-	gridStartX := (common.GridDimPx - totalGridWidth) / 2
+func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image) {
+	hoveredRow, hoveredCol, hovered := -1, -1, false
+	if x, y, ok := pointer.Hover(); ok {
+		hoveredRow, hoveredCol, hovered = t.keyAt(x, y)
+	}
 
 	for row := 0; row < t.keyboardRows; row++ {
 		for col := 0; col < t.keyboardCols; col++ {
@@ -83,8 +97,7 @@ func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
 			}
 
 			// Calculate position
-			x := gridStartX + (col * keySpacingX)
-			y := int(startY) + (row * keySpacingY)
+			x, y := t.keyPosition(row, col)
 
 			// Determine if this key is selected
 			isSelected := row == t.cursorRow && col == t.cursorCol
@@ -94,13 +107,16 @@ func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
 			var textColor color.Color
 
 			if isSelected {
-				textColor = colornames.Cyan
+				textColor = theme.Current().Highlight
 				displayText = "[" + key.displayStr + "]"
+			} else if hovered && row == hoveredRow && col == hoveredCol {
+				textColor = theme.Current().Highlight
+				displayText = key.displayStr
 			} else {
 				if key.special != SpecialKeyNone {
 					textColor = colornames.Orange
 				} else {
-					textColor = colornames.White
+					textColor = theme.Current().Info
 				}
 				displayText = key.displayStr
 			}
@@ -108,8 +124,8 @@ func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
 			// Draw the key
 			// Center the text for this key
 			if key.special == SpecialKeyNone {
-				txtWidth := pxterm24.Font.MeasureString(displayText)
-				pxterm24.Font.DrawString(
+				txtWidth := theme.Current().ScoreFont.MeasureString(displayText)
+				theme.Current().ScoreFont.DrawString(
 					screen,
 					x-txtWidth/2,
 					y,
@@ -117,11 +133,11 @@ func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
 					textColor,
 				)
 			} else {
-				txtWidth := pxterm16.Font.MeasureString(displayText)
-				pxterm16.Font.DrawString(
+				txtWidth := theme.Current().TextFont.MeasureString(displayText)
+				theme.Current().TextFont.DrawString(
 					screen,
 					x-txtWidth/2,
-					y+common.Pxterm16Height/5,
+					y+common.TextLineHeight()/5,
 					displayText,
 					textColor,
 				)
@@ -129,3 +145,33 @@ func (t *TextInput) drawKeyboardGrid(screen *ebiten.Image, startY float64) {
 		}
 	}
 }
+
+// keyPosition returns the point where the label of a key is horizontally centered at and starts from vertically.
+func (t *TextInput) keyPosition(row, col int) (int, int) {
+	keySpacingX := t.keySpacingX()
+	totalGridWidth := (t.keyboardCols - 1) * keySpacingX
+	gridStartX := (common.GridDimPx - totalGridWidth) / 2
+	return gridStartX + (col * keySpacingX), keyboardStartY() + (row * keySpacingY())
+}
+
+// keyAt finds the key whose cell contains a given point of the screen.
+func (t *TextInput) keyAt(x, y int) (row, col int, found bool) {
+	keySpacingX := t.keySpacingX()
+	for row = 0; row < t.keyboardRows; row++ {
+		for col = 0; col < t.keyboardCols; col++ {
+			if t.keyboardGrid[row][col] == nil {
+				continue
+			}
+			keyX, keyY := t.keyPosition(row, col)
+			top := keyY - (keySpacingY()-common.ScoreHeight())/2
+			if x >= keyX-keySpacingX/2 && x < keyX+keySpacingX/2 && y >= top && y < top+keySpacingY() {
+				return row, col, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (t *TextInput) keySpacingX() int {
+	return common.GridDimPx / t.keyboardCols // Horizontal spacing between keys
+}
@@ -189,6 +189,20 @@ func (t *TextInput) ValidateNotEmpty(msg string) *TextInput {
 	return t
 }
 
+// ValidateWith adds a check, which runs once the ones added before have passed.
+func (t *TextInput) ValidateWith(validation func(string) error) *TextInput {
+	previous := t.validation
+	t.validation = func(text string) error {
+		if previous != nil {
+			if err := previous(text); err != nil {
+				return err
+			}
+		}
+		return validation(text)
+	}
+	return t
+}
+
 func (t *TextInput) GetCurrentKey() *KeyboardKey {
 	return t.keyboardGrid[t.cursorRow][t.cursorCol]
 }
@@ -6,6 +6,7 @@ import (
 	"snakehem/input/controller"
 	"snakehem/input/keyboard"
 	"snakehem/input/keyboardwasd"
+	"snakehem/input/pointer"
 	"snakehem/model"
 	"snakehem/util"
 	"unicode"
@@ -23,6 +24,9 @@ func (t *TextInput) Update(ctx *common.Context) {
 		c = keyboard.Instance
 	}
 	t.handleShift()
+	if t.handleClicks() {
+		return
+	}
 	if c.IsUpPressed() {
 		t.moveUp()
 	} else if c.IsDownPressed() {
@@ -40,6 +44,27 @@ func (t *TextInput) Update(ctx *common.Context) {
 	}
 }
 
+// handleClicks lets keys be pressed by clicking them with a mouse or tapping them on a touchscreen.
+// Clicking ENTER submits straight away, since there's no risk of an accidental press.
+func (t *TextInput) handleClicks() bool {
+	handled := false
+	for _, pos := range pointer.AppendJustClicked(nil) {
+		row, col, found := t.keyAt(pos.X, pos.Y)
+		if !found {
+			continue
+		}
+		t.cursorRow = row
+		t.cursorCol = col
+		if t.GetCurrentKey().special == SpecialKeyEnter {
+			t.Submit()
+			return true
+		}
+		t.pressCurrentKey()
+		handled = true
+	}
+	return handled
+}
+
 func (t *TextInput) handleDirectInput() {
 	pressedKeys := inpututil.AppendPressedKeys(nil)
 	for _, pressedKey := range pressedKeys {
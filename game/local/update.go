@@ -7,5 +7,9 @@ import (
 func (c *Content) Update(ctx *common.Context) {
 	if c.textInput != nil {
 		c.textInput.Update(ctx)
+	} else if c.profilePicker != nil {
+		c.profilePicker.Update()
+	} else if c.colourPicker != nil {
+		c.colourPicker.Update()
 	}
 }
@@ -0,0 +1,154 @@
+package bracket
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"snakehem/assets/adhoc8"
+	"snakehem/assets/theme"
+	"snakehem/game/common"
+	"snakehem/input/pointer"
+	"snakehem/tournament"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	nextHeatTxt = "PRESS START BUTTON FOR THE NEXT HEAT"
+	playOnTxt   = "PRESS START BUTTON TO PLAY ON"
+	// columnGapPx is the space between the rounds
+	columnGapPx = 12
+	// lineGapPx is the space between the lines of a heat, when there's room for it
+	lineGapPx = 3
+)
+
+// StartBounds is the area of the START word, which can be clicked to go on to the lobby.
+func StartBounds(t *tournament.Tournament) image.Rectangle {
+	return common.TextCenteredBounds(startTxt(t), startTop(), theme.Current().TextFont)
+}
+
+func promptTxt(t *tournament.Tournament) string {
+	if t.IsOver() {
+		return playOnTxt
+	}
+	return nextHeatTxt
+}
+
+// startTxt blanks out everything but the START word of the prompt.
+func startTxt(t *tournament.Tournament) string {
+	prompt := promptTxt(t)
+	idx := strings.Index(prompt, "START")
+	return strings.Repeat(" ", idx) + "START" + strings.Repeat(" ", len(prompt)-idx-len("START"))
+}
+
+func startTop() float64 {
+	return common.GridDimPx - float64(common.TextLineHeight())*1.5
+}
+
+func bracketTop() int {
+	return common.ScoreHeight() * 3
+}
+
+func bracketBottom() int {
+	return common.GridDimPx - common.TextLineHeight()*3
+}
+
+// Draw shows every round drawn so far as a column of heats. The players going on from a heat are
+// highlighted, and so is the heat to be played next.
+func Draw(screen *ebiten.Image, t *tournament.Tournament) {
+	vector.FillRect(
+		screen,
+		0,
+		0,
+		common.GridDimPx,
+		common.GridDimPx,
+		color.NRGBA{
+			R: 85,
+			G: 107,
+			B: 47,
+			A: 200,
+		},
+		false,
+	)
+	title := "TOURNAMENT"
+	if t.IsOver() {
+		title = "CHAMPION: " + t.Champion
+	}
+	common.DrawTextCentered(screen, title, theme.Current().Text, float64(common.ScoreHeight()), theme.Current().ScoreFont)
+	current, _ := t.CurrentHeat()
+	columnWidth := (common.GridDimPx - columnGapPx*(len(t.Rounds)+1)) / len(t.Rounds)
+	lineHeight := lineHeightToFit(t)
+	for r, round := range t.Rounds {
+		left := columnGapPx + r*(columnWidth+columnGapPx)
+		y := bracketTop()
+		roundTitle := fmt.Sprintf("ROUND %d", r+1)
+		if len(round.Heats) == 1 {
+			roundTitle = "FINAL"
+		}
+		adhoc8.Font.DrawString(screen, left, y, roundTitle, theme.Current().Text)
+		y += lineHeight * 2
+		for i, h := range round.Heats {
+			headerColour := theme.Current().Info
+			if h == current {
+				headerColour = theme.Current().Highlight
+			}
+			adhoc8.Font.DrawString(screen, left, y, fmt.Sprintf("HEAT %d", i+1), headerColour)
+			y += lineHeight
+			drawHeat(screen, t, h, left, y, columnWidth, lineHeight)
+			y += lineHeight * (len(h.Players) + 1)
+		}
+	}
+	common.DrawTextCentered(screen, promptTxt(t), theme.Current().Text, startTop(), theme.Current().TextFont)
+	common.DrawTextCentered(screen, startTxt(t), actionColour(StartBounds(t)), startTop(), theme.Current().TextFont)
+}
+
+// drawHeat lists the players of a heat, with their scores once it's played, dashes for those who forfeited.
+// A player alone in a heat goes on without playing.
+func drawHeat(screen *ebiten.Image, t *tournament.Tournament, h *tournament.Heat, left, top, width, lineHeight int) {
+	var advancing []string
+	if h.IsPlayed() {
+		advancing = t.Advancing(h)
+	}
+	for i, name := range h.Players {
+		y := top + i*lineHeight
+		var colour color.Color = theme.Current().Text
+		if len(h.Players) == 1 {
+			name += " (BYE)"
+			colour = theme.Current().Highlight
+		} else if h.IsPlayed() {
+			colour = theme.Current().Info
+			if slices.Contains(advancing, name) {
+				colour = theme.Current().Highlight
+			}
+			score := fmt.Sprintf(common.ScoreFmt, h.Scores[i])
+			if h.Scores[i] == tournament.Forfeit {
+				score = strings.Repeat("-", len(fmt.Sprintf(common.ScoreFmt, 0)))
+			}
+			adhoc8.Font.DrawString(screen, left+width-adhoc8.Font.MeasureString(score), y, score, colour)
+		}
+		adhoc8.Font.DrawString(screen, left, y, name, colour)
+	}
+}
+
+// lineHeightToFit squeezes the lines together when the longest round wouldn't fit the screen otherwise.
+func lineHeightToFit(t *tournament.Tournament) int {
+	lines := 0
+	for _, round := range t.Rounds {
+		roundLines := 2
+		for _, h := range round.Heats {
+			roundLines += len(h.Players) + 2
+		}
+		lines = max(lines, roundLines)
+	}
+	return min(common.Adhoc8Height+lineGapPx, (bracketBottom()-bracketTop())/lines)
+}
+
+func actionColour(bounds image.Rectangle) color.Color {
+	if pointer.IsHovering(bounds) {
+		return theme.Current().Highlight
+	}
+	return theme.Current().Info
+}
@@ -0,0 +1,78 @@
+package shared
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	whiteImage = ebiten.NewImage(3, 3)
+	// whiteSubImage avoids the edges of whiteImage, so that colours don't bleed when sampling
+	whiteSubImage = whiteImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+)
+
+func init() {
+	whiteImage.Fill(color.White)
+}
+
+// cellBatch collects filled rectangles to draw them all with a single DrawTriangles call.
+// Its slices are kept between frames, so that drawing the grid allocates nothing once they've grown.
+type cellBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+	// unbatched draws every rectangle with a call of its own, for comparing draw times in the perf overlay
+	unbatched bool
+}
+
+func newCellBatch() *cellBatch {
+	return &cellBatch{
+		vertices:  nil,
+		indices:   nil,
+		unbatched: false,
+	}
+}
+
+func (b *cellBatch) Reset() {
+	b.vertices = b.vertices[:0]
+	b.indices = b.indices[:0]
+}
+
+func (b *cellBatch) AddRect(x, y, width, height float32, colour color.Color) {
+	r, g, bl, a := colour.RGBA()
+	cr := float32(r) / 0xffff
+	cg := float32(g) / 0xffff
+	cb := float32(bl) / 0xffff
+	ca := float32(a) / 0xffff
+	idx := uint16(len(b.vertices))
+	for _, corner := range [4][2]float32{{x, y}, {x + width, y}, {x, y + height}, {x + width, y + height}} {
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   corner[0],
+			DstY:   corner[1],
+			SrcX:   1,
+			SrcY:   1,
+			ColorR: cr,
+			ColorG: cg,
+			ColorB: cb,
+			ColorA: ca,
+		})
+	}
+	b.indices = append(b.indices, idx, idx+1, idx+2, idx+1, idx+2, idx+3)
+}
+
+func (b *cellBatch) Draw(screen *ebiten.Image) {
+	if len(b.indices) == 0 {
+		return
+	}
+	opts := &ebiten.DrawTrianglesOptions{}
+	// color.Color.RGBA gives premultiplied values
+	opts.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	if b.unbatched {
+		for i := 0; i < len(b.indices); i += 6 {
+			screen.DrawTriangles(b.vertices, b.indices[i:i+6], whiteSubImage, opts)
+		}
+		return
+	}
+	screen.DrawTriangles(b.vertices, b.indices, whiteSubImage, opts)
+}
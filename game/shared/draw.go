@@ -2,65 +2,115 @@ package shared
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-	"snakehem/assets/pxterm16"
-	"snakehem/assets/pxterm24"
+	"snakehem/assets/adhoc8"
+	"snakehem/assets/theme"
 	"snakehem/game/common"
+	"snakehem/game/shared/bracket"
 	"snakehem/game/shared/snake"
+	"snakehem/input/pointer"
 	"snakehem/model"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/colornames"
 )
 
 const (
 	MaxScoresAtTop = 5
 	EyeRadiusPx    = 2
 	EyeGapPx       = 3
+	// DamageOutlinePx is the width of the frame around hurt links in the colour-blind mode
+	DamageOutlinePx        = 1
+	damageOutlineThreshold = 0.05
+	lobbyStartTxt          = "              START             "
+	// LobbyLabelGapPx is the space between the head of a snake and its label in the lobby
+	LobbyLabelGapPx = 2
 )
 
+// LobbyStartBounds is the area of the START word in the lobby, which can be clicked to start the action.
+func LobbyStartBounds() image.Rectangle {
+	return common.TextCenteredBounds(lobbyStartTxt, common.GridDimPx/2.5, theme.Current().TextFont)
+}
+
+// LobbyThemeBounds is the area of the theme name in the lobby, which can be clicked to switch to the next theme.
+func LobbyThemeBounds() image.Rectangle {
+	return common.TextCenteredBounds(lobbyThemeTxt(), lobbyThemeTop(), theme.Current().TextFont)
+}
+
+func lobbyThemeTxt() string {
+	return fmt.Sprintf("< THEME: %s >", strings.ToUpper(theme.Current().Name))
+}
+
+func lobbyThemeTop() float64 {
+	return common.GridDimPx - float64(common.TextLineHeight())*1.5
+}
+
 func (c *Content) Draw(screen *ebiten.Image) {
-	screen.Fill(colornames.Darkolivegreen)
+	screen.Fill(theme.Current().Background)
 	drawItems(c, screen)
 	switch c.Stage {
 	case Lobby:
 		drawScores(c, screen)
 		snakeCount := len(c.Snakes)
-		if snakeCount < 2 {
+		if !c.CanStart() {
 			common.DrawTextCentered(
 				screen,
 				"PLAYERS PRESS ANY BUTTON TO JOIN",
-				colornames.Yellow,
+				theme.Current().Text,
 				common.GridDimPx/2.5,
-				pxterm16.Font,
+				theme.Current().TextFont,
 			)
+			drawHeatLineup(c, screen)
 		} else {
 			common.DrawTextCentered(
 				screen,
 				"PLAYERS PRESS START BUTTON TO GO",
-				colornames.Yellow,
+				theme.Current().Text,
 				common.GridDimPx/2.5,
-				pxterm16.Font,
+				theme.Current().TextFont,
 			)
 			common.DrawTextCentered(
 				screen,
-				"              START             ",
-				color.White,
+				lobbyStartTxt,
+				actionColour(LobbyStartBounds()),
 				common.GridDimPx/2.5,
-				pxterm16.Font,
+				theme.Current().TextFont,
 			)
-			if snakeCount < model.MaxSnakes {
+			if snakeCount < model.MaxSnakes && c.CurrentHeat() == nil {
 				common.DrawTextCentered(
 					screen,
 					"OR ANY OTHER BUTTON TO JOIN",
-					colornames.Yellow,
-					common.GridDimPx/2.5+float64(common.Pxterm16Height)*1.5,
-					pxterm16.Font,
+					theme.Current().Text,
+					common.GridDimPx/2.5+float64(common.TextLineHeight())*1.5,
+					theme.Current().TextFont,
 				)
 			}
 		}
+		if snakeCount > 0 {
+			common.DrawTextCentered(
+				screen,
+				"SELECT: RENAME  HOLD SELECT: LEAVE",
+				theme.Current().Info,
+				common.GridDimPx/2.5+float64(common.TextLineHeight())*4.5,
+				theme.Current().TextFont,
+			)
+			// Escape may be the select of a player on the arrow keys
+			common.DrawTextCentered(
+				screen,
+				"SHIFT+ESC: QUIT",
+				theme.Current().Info,
+				common.GridDimPx/2.5+float64(common.TextLineHeight())*6,
+				theme.Current().TextFont,
+			)
+		}
+		drawThemePicker(c, screen)
+		for _, s := range c.Snakes {
+			drawLobbyLabel(s, screen)
+		}
 	case Action:
 		if c.FadeCountdown > 0 {
 			vector.FillRect(
@@ -69,111 +119,296 @@ func (c *Content) Draw(screen *ebiten.Image) {
 				0,
 				common.GridDimPx,
 				common.GridDimPx,
-				color.NRGBA{
-					R: 85,
-					G: 107,
-					B: 47,
-					A: uint8((model.GridFadeCountdown - c.FadeCountdown) * 200 / model.GridFadeCountdown),
-				},
+				withAlpha(
+					theme.Current().Background,
+					uint8((model.GridFadeCountdown-c.FadeCountdown)*200/model.GridFadeCountdown),
+				),
 				false,
 			)
 		}
 		drawScores(c, screen)
-		drawCountdown(c, screen)
+		if c.ResumeCountdown > 0 {
+			drawCountdown(screen, c.GetResumeCountdownSeconds(), false)
+		} else {
+			drawCountdown(screen, c.GetCountdownSeconds(), true)
+		}
 		drawTimeElapsed(c, screen)
+		if c.Pause != nil {
+			c.Pause.Draw(screen)
+		}
 	case Scoreboard:
 		c.scoreboard.Draw(screen)
 		drawTimeElapsed(c, screen)
+	case Bracket:
+		bracket.Draw(screen, c.Tournament)
 	}
 }
 
+// drawHeatLineup tells who the tournament heat is waiting for, and who has joined without being in it.
+func drawHeatLineup(c *Content, screen *ebiten.Image) {
+	missing, extra := c.HeatLineup()
+	top := common.GridDimPx/2.5 + float64(common.TextLineHeight())*1.5
+	if len(missing) > 0 {
+		txt := "WAITING FOR: " + strings.Join(missing, ", ")
+		common.DrawTextCentered(screen, txt, theme.Current().Info, top, adhoc8.Font)
+	}
+	if len(extra) > 0 {
+		txt := "NOT IN THIS HEAT: " + strings.Join(extra, ", ")
+		common.DrawTextCentered(screen, txt, theme.Current().Highlight, top+float64(common.Adhoc8Height)*1.5, adhoc8.Font)
+	}
+	if c.CanForfeit() {
+		// in place of the extra players, as there are none
+		txt := "OR PRESS START BUTTON TO PLAY ON WITHOUT THEM, WHO FORFEIT"
+		common.DrawTextCentered(screen, txt, theme.Current().Text, top+float64(common.Adhoc8Height)*1.5, adhoc8.Font)
+	}
+}
+
+// drawThemePicker shows the current theme, and warns when some of the snakes are hard to see on its background.
+func drawThemePicker(c *Content, screen *ebiten.Image) {
+	t := theme.Current()
+	common.DrawTextCentered(screen, lobbyThemeTxt(), actionColour(LobbyThemeBounds()), lobbyThemeTop(), t.TextFont)
+	lowContrastCount := 0
+	for _, s := range c.Snakes {
+		if theme.Contrast(s.Colour, t.Background) < theme.MinContrast {
+			lowContrastCount++
+		}
+	}
+	if lowContrastCount > 0 {
+		common.DrawTextCentered(
+			screen,
+			fmt.Sprintf("%d SNAKE(S) HARD TO SEE ON THIS THEME", lowContrastCount),
+			t.Text,
+			common.GridDimPx/2.5+float64(common.TextLineHeight())*3,
+			t.TextFont,
+		)
+	}
+}
+
+func withAlpha(colour color.Color, alpha uint8) color.Color {
+	c := color.NRGBAModel.Convert(colour).(color.NRGBA)
+	c.A = alpha
+	return c
+}
+
+func actionColour(bounds image.Rectangle) color.Color {
+	if pointer.IsHovering(bounds) {
+		return theme.Current().Highlight
+	}
+	return theme.Current().Info
+}
+
+// drawItems draws the snakes from their links and the apple, batching all the cells into one draw call.
+// Eyes are drawn afterwards, on top of the batch. In the smooth mode, heads and tails slide from their
+// previous cells, so the picture lags one step behind the simulation.
 func drawItems(p *Content, screen *ebiten.Image) {
-	for i := 0; i < model.GridSize; i++ {
-		for j := 0; j < model.GridSize; j++ {
-			if val := p.Grid[i][j]; val != nil {
-				switch item := val.(type) {
-				case *snake.Link:
-					s := p.Snakes[item.SnakeId]
-					shrink := (1 - float32(item.HealthPercent)/100) * common.CellDimPx * 0.5
-					if item != s.Links[0] || p.GetCountdownSeconds() > 0 {
-						vector.FillRect(
-							screen,
-							float32(item.X*common.CellDimPx)+shrink,
-							float32(item.Y*common.CellDimPx)+shrink,
-							common.CellDimPx-shrink*2,
-							common.CellDimPx-shrink*2,
-							common.WithRedness(s.Colour, item.Redness),
-							false,
-						)
-					} else {
-						var x1, y1, x2, y2 float32
-						switch s.Direction {
-						case snake.Up:
-							x1 = float32(item.X*common.CellDimPx) + EyeGapPx
-							y1 = float32(item.Y*common.CellDimPx) + EyeGapPx
-							x2 = float32((item.X+1)*common.CellDimPx) - EyeGapPx
-							y2 = float32(item.Y*common.CellDimPx) + EyeGapPx
-						case snake.Down:
-							x1 = float32(item.X*common.CellDimPx) + EyeGapPx
-							y1 = float32((item.Y+1)*common.CellDimPx) - EyeGapPx
-							x2 = float32((item.X+1)*common.CellDimPx) - EyeGapPx
-							y2 = float32((item.Y+1)*common.CellDimPx) - EyeGapPx
-						case snake.Left:
-							x1 = float32(item.X*common.CellDimPx) + EyeGapPx
-							y1 = float32((item.Y+1)*common.CellDimPx) - EyeGapPx
-							x2 = float32(item.X*common.CellDimPx) + EyeGapPx
-							y2 = float32(item.Y*common.CellDimPx) + EyeGapPx
-						case snake.Right:
-							x1 = float32((item.X+1)*common.CellDimPx) - EyeGapPx
-							y1 = float32((item.Y+1)*common.CellDimPx) - EyeGapPx
-							x2 = float32((item.X+1)*common.CellDimPx) - EyeGapPx
-							y2 = float32(item.Y*common.CellDimPx) + EyeGapPx
-						case snake.None:
-						}
-						if x1 != 0 || y1 != 0 || x2 != 0 || y2 != 0 {
-							vector.FillCircle(
-								screen,
-								x1,
-								y1,
-								EyeRadiusPx,
-								common.WithRedness(s.Colour, item.Redness),
-								false,
-							)
-							vector.FillCircle(
-								screen,
-								x2,
-								y2,
-								EyeRadiusPx,
-								common.WithRedness(s.Colour, item.Redness),
-								false,
-							)
-						} else {
-							vector.FillRect(
-								screen,
-								float32(item.X*common.CellDimPx),
-								float32(item.Y*common.CellDimPx),
-								common.CellDimPx,
-								common.CellDimPx,
-								common.WithRedness(s.Colour, item.Redness),
-								false,
-							)
-						}
-					}
-				}
+	p.cells.Reset()
+	if lines := theme.Current().GridLines; lines != nil {
+		for i := 1; i < model.GridSize; i++ {
+			pos := float32(i*common.CellDimPx) - 0.5
+			p.cells.AddRect(pos, 0, 1, common.GridDimPx, lines)
+			p.cells.AddRect(0, pos, common.GridDimPx, 1, lines)
+		}
+	}
+	progress := p.stepProgress()
+	for _, s := range p.Snakes {
+		head := s.Links[0]
+		for i, link := range s.Links {
+			x := float32(link.X * common.CellDimPx)
+			y := float32(link.Y * common.CellDimPx)
+			if link == head {
+				x, y = interpolate(link, progress)
+			}
+			if link != head || p.GetCountdownSeconds() > 0 {
+				shrink := (1 - float32(link.HealthPercent)/100) * common.CellDimPx * 0.5
+				p.addLink(s, i, x+shrink, y+shrink, common.CellDimPx-shrink*2)
+			} else if s.Direction == snake.None {
+				p.addLink(s, i, x, y, common.CellDimPx)
 			}
 		}
+		if tail := s.Links[len(s.Links)-1]; p.Smooth && tail != head {
+			// the tail leaves its previous cell gradually
+			x, y := interpolate(tail, progress)
+			shrink := (1 - float32(tail.HealthPercent)/100) * common.CellDimPx * 0.5
+			p.addLink(s, len(s.Links)-1, x+shrink, y+shrink, common.CellDimPx-shrink*2)
+		}
 	}
 	if a := p.applePos; a != nil {
-		vector.FillRect(
-			screen,
-			float32(a.X*common.CellDimPx),
-			float32(a.Y*common.CellDimPx),
-			common.CellDimPx,
-			common.CellDimPx,
-			colornames.Red,
-			false,
-		)
+		p.addCell(float32(a.X*common.CellDimPx), float32(a.Y*common.CellDimPx), common.CellDimPx, theme.Current().Apple)
+	}
+	p.cells.Draw(screen)
+	if p.GetCountdownSeconds() <= 0 {
+		for _, s := range p.Snakes {
+			drawEyes(s, screen, progress)
+		}
+	}
+	if theme.IsColourBlindMode() {
+		for _, s := range p.Snakes {
+			drawHeadNumber(p, s, screen, progress)
+		}
+	}
+}
+
+// pattern tells snakes apart in the colour-blind mode, besides their colours and head numbers.
+type pattern uint8
+
+const (
+	solid pattern = iota
+	dotted
+	hollow
+	striped
+	patternCount
+)
+
+func patternOf(s *snake.Snake) pattern {
+	return pattern(s.Id % int(patternCount))
+}
+
+// addLink adds the i-th link of a snake to the batch. In the colour-blind mode, the body gets the pattern
+// of the snake, and a damaged link is outlined, the brighter the more it's hurt, instead of turning red.
+func (c *Content) addLink(s *snake.Snake, i int, x, y, side float32) {
+	link := s.Links[i]
+	if !theme.IsColourBlindMode() {
+		c.addCell(x, y, side, common.WithRedness(s.Colour, link.Redness))
+		return
+	}
+	c.addCell(x, y, side, s.Colour)
+	marker := common.ContrastingColour(s.Colour)
+	if i > 0 {
+		switch patternOf(s) {
+		case dotted:
+			dot := side / 3
+			c.addCell(x+(side-dot)/2, y+(side-dot)/2, dot, marker)
+		case hollow:
+			hole := side / 2
+			c.addCell(x+(side-hole)/2, y+(side-hole)/2, hole, theme.Current().Background)
+		case striped:
+			if i%2 == 1 {
+				c.addCell(x, y, side, withAlpha(marker, 100))
+			}
+		case solid, patternCount:
+		}
+	}
+	if link.Redness > damageOutlineThreshold {
+		outline := withAlpha(marker, uint8(255*link.Redness))
+		c.cells.AddRect(x, y, side, DamageOutlinePx, outline)
+		c.cells.AddRect(x, y+side-DamageOutlinePx, side, DamageOutlinePx, outline)
+		c.cells.AddRect(x, y+DamageOutlinePx, DamageOutlinePx, side-2*DamageOutlinePx, outline)
+		c.cells.AddRect(x+side-DamageOutlinePx, y+DamageOutlinePx, DamageOutlinePx, side-2*DamageOutlinePx, outline)
+	}
+}
+
+// drawHeadNumber marks the head with the player number, for the colour-blind mode.
+func drawHeadNumber(p *Content, s *snake.Snake, screen *ebiten.Image, progress float32) {
+	x, y := interpolate(s.Links[0], progress)
+	txt := strconv.Itoa(s.Id + 1)
+	colour := s.Colour
+	if p.GetCountdownSeconds() > 0 || s.Direction == snake.None {
+		// the head is a filled square then, rather than just the eyes
+		colour = common.ContrastingColour(s.Colour)
+	}
+	adhoc8.Font.DrawString(
+		screen,
+		int(x)+(common.CellDimPx-adhoc8.Font.MeasureString(txt))/2,
+		int(y)+(common.CellDimPx-common.Adhoc8Height)/2,
+		txt,
+		colour,
+	)
+}
+
+// drawLobbyLabel puts the name and the skill rating of a player under the head of their snake, and their
+// rumble intensity below.
+func drawLobbyLabel(s *snake.Snake, screen *ebiten.Image) {
+	head := s.Links[0]
+	txt := fmt.Sprintf("%s %d", s.Name, s.Rating)
+	width := adhoc8.Font.MeasureString(txt)
+	x := head.X*common.CellDimPx + (common.CellDimPx-width)/2
+	x = max(0, min(common.GridDimPx-width, x))
+	top := (head.Y+1)*common.CellDimPx + LobbyLabelGapPx
+	adhoc8.Font.DrawString(screen, x, top, txt, s.Colour)
+	// up and down change it, which players without rumble wouldn't notice otherwise
+	rumbleTxt := "RUMBLE " + s.Rumble.String()
+	width = adhoc8.Font.MeasureString(rumbleTxt)
+	x = head.X*common.CellDimPx + (common.CellDimPx-width)/2
+	x = max(0, min(common.GridDimPx-width, x))
+	adhoc8.Font.DrawString(screen, x, top+common.Adhoc8Height+LobbyLabelGapPx, rumbleTxt, theme.Current().Info)
+}
+
+// stepProgress tells how far the snakes have gone from their previous cells to the current ones, from 0 to 1.
+func (c *Content) stepProgress() float32 {
+	if !c.Smooth {
+		return 1
+	}
+	// snakes step when ActionFrameCount is a multiple of TpsMultiplier, right before it's incremented
+	return float32((c.ActionFrameCount+model.TpsMultiplier-1)%model.TpsMultiplier) / model.TpsMultiplier
+}
+
+// interpolate gives the position of a link in pixels, somewhere between its previous cell and the current one.
+// Crossing the edge of the grid, the link moves towards the outside rather than across the whole grid.
+func interpolate(link *snake.Link, progress float32) (float32, float32) {
+	return interpolateAxis(link.PrevX, link.X, progress), interpolateAxis(link.PrevY, link.Y, progress)
+}
+
+func interpolateAxis(prev, cur int, progress float32) float32 {
+	delta := cur - prev
+	if delta > 1 {
+		delta -= model.GridSize
+	} else if delta < -1 {
+		delta += model.GridSize
+	}
+	return (float32(prev) + float32(delta)*progress) * common.CellDimPx
+}
+
+// addCell adds a square to the batch, and also its copies on the opposite sides if it sticks out of the grid.
+func (c *Content) addCell(x, y, side float32, colour color.Color) {
+	c.cells.AddRect(x, y, side, side, colour)
+	dx := wrapOffset(x, side)
+	dy := wrapOffset(y, side)
+	if dx != 0 {
+		c.cells.AddRect(x+dx, y, side, side, colour)
+	}
+	if dy != 0 {
+		c.cells.AddRect(x, y+dy, side, side, colour)
+	}
+	if dx != 0 && dy != 0 {
+		c.cells.AddRect(x+dx, y+dy, side, side, colour)
+	}
+}
+
+func wrapOffset(pos, side float32) float32 {
+	if pos < 0 {
+		return common.GridDimPx
+	}
+	if pos+side > common.GridDimPx {
+		return -common.GridDimPx
+	}
+	return 0
+}
+
+func drawEyes(s *snake.Snake, screen *ebiten.Image, progress float32) {
+	head := s.Links[0]
+	left, top := interpolate(head, progress)
+	right := left + common.CellDimPx
+	bottom := top + common.CellDimPx
+	var x1, y1, x2, y2 float32
+	switch s.Direction {
+	case snake.Up:
+		x1, y1 = left+EyeGapPx, top+EyeGapPx
+		x2, y2 = right-EyeGapPx, top+EyeGapPx
+	case snake.Down:
+		x1, y1 = left+EyeGapPx, bottom-EyeGapPx
+		x2, y2 = right-EyeGapPx, bottom-EyeGapPx
+	case snake.Left:
+		x1, y1 = left+EyeGapPx, bottom-EyeGapPx
+		x2, y2 = left+EyeGapPx, top+EyeGapPx
+	case snake.Right:
+		x1, y1 = right-EyeGapPx, bottom-EyeGapPx
+		x2, y2 = right-EyeGapPx, top+EyeGapPx
+	case snake.None:
+		return
 	}
+	colour := common.LinkColour(s.Colour, head.Redness)
+	vector.FillCircle(screen, x1, y1, EyeRadiusPx, colour, false)
+	vector.FillCircle(screen, x2, y2, EyeRadiusPx, colour, false)
 }
 
 func drawScores(p *Content, screen *ebiten.Image) {
@@ -182,18 +417,18 @@ func drawScores(p *Content, screen *ebiten.Image) {
 	if scoresAtTop > MaxScoresAtTop {
 		scoresAtTop = MaxScoresAtTop
 	}
-	drawScoreRow(p, screen, snakes[:scoresAtTop], common.Pxterm24Height/2)
+	drawScoreRow(p, screen, snakes[:scoresAtTop], common.ScoreHeight()/2)
 	// when there are many players, not all scores can be fit in one line
-	drawScoreRow(p, screen, snakes[scoresAtTop:], common.GridDimPx-common.Pxterm24Height-common.Pxterm16Height*2)
+	drawScoreRow(p, screen, snakes[scoresAtTop:], common.GridDimPx-common.ScoreHeight()-common.TextLineHeight()*2)
 }
 
 func drawScoreRow(p *Content, screen *ebiten.Image, snakes []*snake.Snake, rowTopPos int) {
 	span := float64(screen.Bounds().Dx()) / float64(len(snakes))
 	for i, s := range snakes {
-		if p.Stage != Action || s.Score+model.ApproachingTargetScoreGap < model.TargetScore || (p.ActionFrameCount/(model.Tps/4))%2 > 0 {
+		if p.Stage != Action || !IsApproachingTarget(s.Score) || (p.ActionFrameCount/(model.Tps/4))%2 > 0 {
 			txt, colour := scoreStrAndColourForIthSnake(p, s)
-			x := int(span*float64(i) + span/2 - float64(pxterm24.Font.MeasureString(txt))/2 + 2)
-			pxterm24.Font.DrawString(screen, x, rowTopPos, txt, colour)
+			x := int(span*float64(i) + span/2 - float64(theme.Current().ScoreFont.MeasureString(txt))/2 + 2)
+			theme.Current().ScoreFont.DrawString(screen, x, rowTopPos, txt, colour)
 		}
 	}
 }
@@ -208,7 +443,7 @@ func scoreStrAndColourForIthSnake(p *Content, snake *snake.Snake) (string, color
 	if p.Stage == Action && p.GetCountdownSeconds() < 1 {
 		colour = snake.Colour
 	} else {
-		colour = common.WithRedness(snake.Colour, snake.Links[0].Redness)
+		colour = common.LinkColour(snake.Colour, snake.Links[0].Redness)
 	}
 	return txt, colour
 }
@@ -218,14 +453,13 @@ func drawTimeElapsed(p *Content, screen *ebiten.Image) {
 	common.DrawTextCentered(
 		screen,
 		t.Format("04:05.0"),
-		colornames.White,
-		common.GridDimPx-float64(common.Pxterm16Height)*1.5,
-		pxterm16.Font,
+		theme.Current().Info,
+		common.GridDimPx-float64(common.TextLineHeight())*1.5,
+		theme.Current().TextFont,
 	)
 }
 
-func drawCountdown(p *Content, screen *ebiten.Image) {
-	countdown := p.GetCountdownSeconds()
+func drawCountdown(screen *ebiten.Image, countdown int, withTargetScore bool) {
 	if countdown < 0 {
 		return
 	}
@@ -242,14 +476,14 @@ func drawCountdown(p *Content, screen *ebiten.Image) {
 	default:
 		txt = "WAIT..."
 	}
-	common.DrawTextCentered(screen, txt, color.White, common.GridDimPx/2.5, pxterm24.Font)
-	if countdown > 0 {
+	common.DrawTextCentered(screen, txt, theme.Current().Info, common.GridDimPx/2.5, theme.Current().ScoreFont)
+	if countdown > 0 && withTargetScore {
 		common.DrawTextCentered(
 			screen,
 			fmt.Sprintf("TARGET SCORE: %d", model.TargetScore),
-			colornames.Yellow,
-			common.GridDimPx/2.5+float64(common.Pxterm24Height*2),
-			pxterm24.Font,
+			theme.Current().Text,
+			common.GridDimPx/2.5+float64(common.ScoreHeight()*2),
+			theme.Current().ScoreFont,
 		)
 	}
 }
@@ -0,0 +1,138 @@
+package event
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Event is something which happens during a match. Type names the event in the event log.
+type Event interface {
+	Type() string
+}
+
+// MatchStarted is emitted when the countdown of a match begins.
+type MatchStarted struct {
+	// Players are the names of the players, indexed by snake id
+	Players     []string `json:"players"`
+	TargetScore int      `json:"targetScore"`
+}
+
+type DirectionChanged struct {
+	SnakeId   int    `json:"snakeId"`
+	Direction string `json:"direction"`
+}
+
+// LinkBitten is emitted for every bite, including the ones a snake gives itself.
+type LinkBitten struct {
+	SnakeId       int `json:"snakeId"`
+	TargetSnakeId int `json:"targetSnakeId"`
+	// LinkIndex counts from the head of the target snake
+	LinkIndex     int `json:"linkIndex"`
+	HealthPercent int `json:"healthPercent"`
+	X             int `json:"x"`
+	Y             int `json:"y"`
+}
+
+// TailNipped follows the LinkBitten which has taken the last health of a link,
+// cutting off the link and everything behind it.
+type TailNipped struct {
+	SnakeId       int      `json:"snakeId"`
+	TargetSnakeId int      `json:"targetSnakeId"`
+	Length        int      `json:"length"`
+	Cells         [][2]int `json:"cells"`
+}
+
+type AppleSpawned struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type AppleEaten struct {
+	SnakeId int `json:"snakeId"`
+	X       int `json:"x"`
+	Y       int `json:"y"`
+}
+
+type ScoreChanged struct {
+	SnakeId int `json:"snakeId"`
+	Delta   int `json:"delta"`
+	Score   int `json:"score"`
+}
+
+// MatchEnded is emitted when a snake reaches the target score and the scoreboard comes up,
+// or, with Aborted set, when the match is left from the pause menu.
+type MatchEnded struct {
+	// Scores are indexed by snake id
+	Scores  []int `json:"scores"`
+	Aborted bool  `json:"aborted"`
+}
+
+func (MatchStarted) Type() string     { return "MatchStarted" }
+func (DirectionChanged) Type() string { return "DirectionChanged" }
+func (LinkBitten) Type() string       { return "LinkBitten" }
+func (TailNipped) Type() string       { return "TailNipped" }
+func (AppleSpawned) Type() string     { return "AppleSpawned" }
+func (AppleEaten) Type() string       { return "AppleEaten" }
+func (ScoreChanged) Type() string     { return "ScoreChanged" }
+func (MatchEnded) Type() string       { return "MatchEnded" }
+
+type handler func(tick uint64, e Event)
+
+// Bus hands the events over to the subscribers straight away, in the order they subscribed.
+// Events are stamped with the tick of the match they happened on.
+type Bus struct {
+	tick     func() uint64
+	handlers []handler
+}
+
+func NewBus(tick func() uint64) *Bus {
+	return &Bus{
+		tick:     tick,
+		handlers: nil,
+	}
+}
+
+func (b *Bus) Emit(e Event) {
+	tick := b.tick()
+	for _, h := range b.handlers {
+		h(tick, e)
+	}
+}
+
+// SubscribeAll gets every event, whatever its type.
+func (b *Bus) SubscribeAll(h func(tick uint64, e Event)) {
+	b.handlers = append(b.handlers, h)
+}
+
+// Subscribe gets the events of a single type.
+func Subscribe[E Event](b *Bus, h func(tick uint64, e E)) {
+	b.SubscribeAll(func(tick uint64, e Event) {
+		if typed, ok := e.(E); ok {
+			h(tick, typed)
+		}
+	})
+}
+
+type record struct {
+	Tick  uint64 `json:"tick"`
+	Type  string `json:"type"`
+	Event Event  `json:"event"`
+}
+
+// WriteLog writes every event as a line of JSON. Writes aren't buffered, so that nothing is lost
+// when the game exits. A failed write is logged and the log is given up on.
+func WriteLog(b *Bus, w io.Writer) {
+	encoder := json.NewEncoder(w)
+	failed := false
+	b.SubscribeAll(func(tick uint64, e Event) {
+		if failed {
+			return
+		}
+		if err := encoder.Encode(record{Tick: tick, Type: e.Type(), Event: e}); err != nil {
+			failed = true
+			log.Error().Err(err).Msg("Failed to write the event log")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package pausemenu
+
+import (
+	"image/color"
+	"snakehem/assets/theme"
+	"snakehem/game/common"
+	"snakehem/input/pointer"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const itemsStartY = common.GridDimPx * 3 / 8
+
+// itemHeight grows with the font of the theme, leaving a third of its height between the items.
+func itemHeight() int {
+	return common.ScoreHeight() * 5 / 3
+}
+
+func (m *PauseMenu) Draw(screen *ebiten.Image) {
+	vector.FillRect(
+		screen,
+		0,
+		0,
+		common.GridDimPx,
+		common.GridDimPx,
+		color.NRGBA{
+			R: 85,
+			G: 107,
+			B: 47,
+			A: 200,
+		},
+		false,
+	)
+	title := "PAUSED"
+	if m.IsConfirmingQuit() {
+		title = "QUIT THE GAME?"
+	}
+	common.DrawTextCentered(screen, title, theme.Current().Text, common.GridDimPx/4.0, theme.Current().ScoreFont)
+	hovered, isHovering := m.hoveredItem()
+	for i, item := range m.items {
+		txt := item.String()
+		var colour color.Color = theme.Current().Info
+		if i == m.cursor {
+			txt = "[" + txt + "]"
+			colour = theme.Current().Highlight
+		} else if isHovering && item == hovered {
+			colour = theme.Current().Highlight
+		}
+		common.DrawTextCentered(screen, txt, colour, float64(itemsStartY+i*itemHeight()), theme.Current().ScoreFont)
+	}
+	instructionsY := common.GridDimPx - float64(common.TextLineHeight())*2.5
+	common.DrawTextCentered(screen, "UP/DOWN: CHOOSE", theme.Current().Text, instructionsY, theme.Current().TextFont)
+	common.DrawTextCentered(screen, "START: CONFIRM  SELECT: BACK", theme.Current().Text, instructionsY+float64(common.TextLineHeight()), theme.Current().TextFont)
+}
+
+// ItemAt finds the menu item drawn at a given vertical position of the screen.
+func (m *PauseMenu) ItemAt(y int) (Item, bool) {
+	top := itemsStartY - (itemHeight()-common.ScoreHeight())/2
+	if y < top {
+		return 0, false
+	}
+	i := (y - top) / itemHeight()
+	if i >= len(m.items) {
+		return 0, false
+	}
+	return m.items[i], true
+}
+
+func (m *PauseMenu) hoveredItem() (Item, bool) {
+	if _, y, ok := pointer.Hover(); ok {
+		return m.ItemAt(y)
+	}
+	return 0, false
+}
@@ -0,0 +1,84 @@
+package pausemenu
+
+type Item uint8
+
+const (
+	Resume Item = iota
+	RestartRound
+	BackToLobby
+	Quit
+	QuitCancelled
+	QuitConfirmed
+)
+
+func (i Item) String() string {
+	switch i {
+	case Resume:
+		return "RESUME"
+	case RestartRound:
+		return "RESTART ROUND"
+	case BackToLobby:
+		return "BACK TO LOBBY"
+	case Quit:
+		return "QUIT"
+	case QuitCancelled:
+		return "NO"
+	case QuitConfirmed:
+		return "YES"
+	default:
+		return ""
+	}
+}
+
+var (
+	mainItems    = []Item{Resume, RestartRound, BackToLobby, Quit}
+	confirmItems = []Item{QuitCancelled, QuitConfirmed}
+)
+
+type PauseMenu struct {
+	items  []Item
+	cursor int
+}
+
+func NewPauseMenu() *PauseMenu {
+	return &PauseMenu{
+		items:  mainItems,
+		cursor: 0,
+	}
+}
+
+func (m *PauseMenu) IsConfirmingQuit() bool {
+	return len(m.items) == len(confirmItems)
+}
+
+func (m *PauseMenu) Selected() Item {
+	return m.items[m.cursor]
+}
+
+func (m *PauseMenu) Select(item Item) {
+	for i, it := range m.items {
+		if it == item {
+			m.cursor = i
+		}
+	}
+}
+
+func (m *PauseMenu) MoveUp() {
+	m.cursor = (m.cursor + len(m.items) - 1) % len(m.items)
+}
+
+func (m *PauseMenu) MoveDown() {
+	m.cursor = (m.cursor + 1) % len(m.items)
+}
+
+// AskToConfirmQuit replaces the menu with a yes/no question, defaulting to no.
+func (m *PauseMenu) AskToConfirmQuit() {
+	m.items = confirmItems
+	m.cursor = 0
+}
+
+// Back leaves the quit confirmation, returning to the main menu.
+func (m *PauseMenu) Back() {
+	m.items = mainItems
+	m.Select(Quit)
+}
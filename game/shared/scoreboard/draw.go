@@ -2,18 +2,75 @@ package scoreboard
 
 import (
 	"fmt"
+	"image"
 	"image/color"
-	"snakehem/assets/pxterm16"
-	"snakehem/assets/pxterm24"
+	"slices"
+	"snakehem/assets/adhoc8"
+	"snakehem/assets/theme"
 	"snakehem/game/common"
+	"snakehem/game/shared/stats"
+	"snakehem/input/pointer"
 	"snakehem/model"
 	"snakehem/util"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/colornames"
+	"github.com/pbnjay/pixfont"
 )
 
+const (
+	startTxt = "      START                     "
+	quitTxt  = "   SELECT               "
+)
+
+// StartBounds is the area of the START word, which can be clicked to play again.
+func StartBounds() image.Rectangle {
+	return common.TextCenteredBounds(startTxt, startTop(), theme.Current().TextFont)
+}
+
+// QuitBounds is the area of the SELECT word, which can be clicked to quit.
+func QuitBounds() image.Rectangle {
+	return common.TextCenteredBounds(quitTxt, quitTop(), theme.Current().TextFont)
+}
+
+// navTxt is as long for every page, so that the arrows stay in place.
+func navTxt(page Page) string {
+	title := pageTitles[page]
+	pad := navWidth - 2 - len(title)
+	return "<" + strings.Repeat(" ", pad/2) + title + strings.Repeat(" ", pad-pad/2) + ">"
+}
+
+const navWidth = 16
+
+// PreviousPageBounds is the area of the left arrow under the pages, which can be clicked to flip back.
+func PreviousPageBounds() image.Rectangle {
+	return common.TextCenteredBounds("<"+strings.Repeat(" ", navWidth-1), navTop(), theme.Current().TextFont)
+}
+
+func NextPageBounds() image.Rectangle {
+	return common.TextCenteredBounds(strings.Repeat(" ", navWidth-1)+">", navTop(), theme.Current().TextFont)
+}
+
+func navTop() float64 {
+	return float64(common.GridDimPx - common.ScoreHeight()*2)
+}
+
+func startTop() float64 {
+	return float64(common.ScoreHeight()*2 + common.TextLineHeight())
+}
+
+func quitTop() float64 {
+	return float64(common.ScoreHeight()*2 + common.TextLineHeight()*2)
+}
+
+func actionColour(bounds image.Rectangle) color.Color {
+	if pointer.IsHovering(bounds) {
+		return theme.Current().Highlight
+	}
+	return theme.Current().Info
+}
+
 func (s *Scoreboard) Draw(screen *ebiten.Image) {
 	vector.FillRect(
 		screen,
@@ -32,45 +89,224 @@ func (s *Scoreboard) Draw(screen *ebiten.Image) {
 	common.DrawTextCentered(
 		screen,
 		"GAME OVER",
-		colornames.Yellow,
-		float64(common.Pxterm24Height),
-		pxterm24.Font,
+		theme.Current().Text,
+		float64(common.ScoreHeight()),
+		theme.Current().ScoreFont,
 	)
 	common.DrawTextCentered(
 		screen,
 		"PRESS START BUTTON TO PLAY AGAIN",
-		colornames.Yellow,
-		float64(common.Pxterm24Height*2+common.Pxterm16Height),
-		pxterm16.Font,
+		theme.Current().Text,
+		startTop(),
+		theme.Current().TextFont,
 	)
 	common.DrawTextCentered(
 		screen,
-		"      START                     ",
-		color.White,
-		float64(common.Pxterm24Height*2+common.Pxterm16Height),
-		pxterm16.Font,
+		startTxt,
+		actionColour(StartBounds()),
+		startTop(),
+		theme.Current().TextFont,
 	)
 	common.DrawTextCentered(
 		screen,
 		"OR SELECT BUTTON TO QUIT",
-		colornames.Yellow,
-		float64(common.Pxterm24Height*2+common.Pxterm16Height*2),
-		pxterm16.Font,
+		theme.Current().Text,
+		quitTop(),
+		theme.Current().TextFont,
 	)
 	common.DrawTextCentered(
 		screen,
-		"   SELECT               ",
-		color.White,
-		float64(common.Pxterm24Height*2+common.Pxterm16Height*2),
-		pxterm16.Font,
+		quitTxt,
+		actionColour(QuitBounds()),
+		quitTop(),
+		theme.Current().TextFont,
 	)
+	switch s.page {
+	case Scores:
+		s.drawScores(screen)
+	case Rivalry:
+		s.drawRivalry(screen)
+	case Nemeses:
+		s.drawNemeses(screen)
+	case Awards:
+		s.drawAwards(screen)
+	default:
+		s.drawStats(screen, pageColumns[s.page])
+	}
+	common.DrawTextCentered(screen, navTxt(s.page), theme.Current().Text, navTop(), theme.Current().TextFont)
+	common.DrawTextCentered(screen, "<"+strings.Repeat(" ", navWidth-1), actionColour(PreviousPageBounds()), navTop(), theme.Current().TextFont)
+	common.DrawTextCentered(screen, strings.Repeat(" ", navWidth-1)+">", actionColour(NextPageBounds()), navTop(), theme.Current().TextFont)
+}
+
+func (s *Scoreboard) drawScores(screen *ebiten.Image) {
 	for i, e := range s.entries {
 		common.DrawTextCentered(
 			screen,
 			fmt.Sprintf("%s "+common.ScoreFmt, util.PadRight(e.Name, model.MaxNameLength), e.Score),
 			e.ColourFunc(),
-			float64(common.Pxterm24Height*2*(i+3)),
-			pxterm24.Font,
+			float64(common.ScoreHeight()*2*(i+3)),
+			theme.Current().ScoreFont,
+		)
+	}
+}
+
+type column struct {
+	title string
+	value func(stats.Stats) string
+}
+
+func count(value func(stats.Stats) int) func(stats.Stats) string {
+	return func(s stats.Stats) string {
+		return fmt.Sprint(value(s))
+	}
+}
+
+var pageColumns = map[Page][]column{
+	Fighting: {
+		{"BITES", count(func(s stats.Stats) int { return s.BitesDealt })},
+		{"BITTEN", count(func(s stats.Stats) int { return s.BitesReceived })},
+		{"SELF", count(func(s stats.Stats) int { return s.SelfBites })},
+	},
+	Nipping: {
+		{"TAILS", count(func(s stats.Stats) int { return s.TailsNipped })},
+		{"LINKS", count(func(s stats.Stats) int { return s.LinksTaken })},
+		{"APPLES", count(func(s stats.Stats) int { return s.ApplesEaten })},
+	},
+	Moving: {
+		{"LONGEST", count(func(s stats.Stats) int { return s.LongestLength })},
+		{"FULL LEN", func(s stats.Stats) string { return fmt.Sprintf("%dS", s.SecondsAtFullLength()) }},
+		{"DISTANCE", count(func(s stats.Stats) int { return s.Distance })},
+	},
+}
+
+// drawStats lays the stats out as a table, with a row per player below the column titles.
+func (s *Scoreboard) drawStats(screen *ebiten.Image, columns []column) {
+	header := strings.Repeat(" ", model.MaxNameLength)
+	for _, c := range columns {
+		header += fmt.Sprintf(" %8s", c.title)
+	}
+	common.DrawTextCentered(screen, header, theme.Current().Info, float64(common.ScoreHeight()*2*3), theme.Current().TextFont)
+	for i, e := range s.entries {
+		row := util.PadRight(e.Name, model.MaxNameLength)
+		for _, c := range columns {
+			row += fmt.Sprintf(" %8s", c.value(e.Stats))
+		}
+		common.DrawTextCentered(screen, row, e.ColourFunc(), float64(common.ScoreHeight()*2*(i+4)), theme.Current().TextFont)
+	}
+}
+
+const (
+	rivalryRowPx = 32
+	// rivalryLabelPx is the size of the coloured squares which stand for the players at the edges of the grid
+	rivalryLabelPx = 16
+)
+
+// drawRivalry shows who has bitten whom as a grid: each cell holds the bites and nips the player of its row
+// has dealt to the player of its column. The cell of each player's nemesis is outlined.
+func (s *Scoreboard) drawRivalry(screen *ebiten.Image) {
+	cells := make([][]string, len(s.entries))
+	for row, biter := range s.entries {
+		cells[row] = make([]string, len(s.entries))
+		for col, bitten := range s.entries {
+			cells[row][col] = "-"
+			if row != col {
+				cells[row][col] = fmt.Sprintf("%d/%d", biter.Stats.BitesOn[bitten.SnakeId], biter.Stats.NipsOn[bitten.SnakeId])
+			}
+		}
+	}
+	font, cellPx := rivalryCellFont(cells, len(s.entries))
+	left := (common.GridDimPx - rivalryLabelPx*2 - cellPx*len(s.entries)) / 2
+	cellsLeft := left + rivalryLabelPx*2
+	top := common.ScoreHeight() * 2 * 3
+	for i, e := range s.entries {
+		colour := e.ColourFunc()
+		x := cellsLeft + cellPx*i + (cellPx-rivalryLabelPx)/2
+		vector.FillRect(screen, float32(x), float32(top), rivalryLabelPx, rivalryLabelPx, colour, false)
+		y := top + rivalryRowPx*(i+1)
+		vector.FillRect(screen, float32(left), float32(y), rivalryLabelPx, rivalryLabelPx, colour, false)
+	}
+	for row, biter := range s.entries {
+		y := top + rivalryRowPx*(row+1)
+		for col := range s.entries {
+			x := cellsLeft + cellPx*col
+			txt := cells[row][col]
+			font.DrawString(screen, x+(cellPx-font.MeasureString(txt))/2, y, txt, biter.ColourFunc())
+			if s.nemeses[col] == row {
+				vector.StrokeRect(screen, float32(x), float32(y-4), float32(cellPx), rivalryLabelPx+8, 2, theme.Current().Highlight, false)
+			}
+		}
+	}
+	common.DrawTextCentered(
+		screen,
+		"BITES/NIPS BY ROW ON COLUMN",
+		theme.Current().Info,
+		float64(top+rivalryRowPx*(len(s.entries)+1)+rivalryLabelPx),
+		theme.Current().TextFont,
+	)
+}
+
+// rivalryCellFont makes the cells as wide as the widest value, with a space to spare. Should they not fit
+// the screen in the font of the theme, the small font is used.
+func rivalryCellFont(cells [][]string, count int) (*pixfont.PixFont, int) {
+	font := theme.Current().TextFont
+	cellPx := widestCellPx(cells, font)
+	if rivalryLabelPx*2+cellPx*count > common.GridDimPx {
+		font = adhoc8.Font
+		cellPx = widestCellPx(cells, font)
+	}
+	return font, cellPx
+}
+
+func widestCellPx(cells [][]string, font *pixfont.PixFont) int {
+	widest := 0
+	for _, row := range cells {
+		for _, txt := range row {
+			widest = max(widest, font.MeasureString(txt+" "))
+		}
+	}
+	return widest
+}
+
+// drawNemeses lists who has hurt each player the most, in this match and over all recorded ones.
+func (s *Scoreboard) drawNemeses(screen *ebiten.Image) {
+	rowFmt := "%s %s %s"
+	common.DrawTextCentered(
+		screen,
+		fmt.Sprintf(rowFmt, strings.Repeat(" ", model.MaxNameLength), util.PadRight("MATCH", model.MaxNameLength), util.PadRight("ALL TIME", model.MaxNameLength)),
+		theme.Current().Info,
+		float64(common.ScoreHeight()*2*3),
+		theme.Current().TextFont,
+	)
+	blankName := strings.Repeat(" ", model.MaxNameLength)
+	for i, e := range s.entries {
+		top := float64(common.ScoreHeight() * 2 * (i + 4))
+		// each name is drawn in the colour of its snake, with the rest of the row blanked out
+		common.DrawTextCentered(screen, fmt.Sprintf(rowFmt, util.PadRight(e.Name, model.MaxNameLength), blankName, blankName), e.ColourFunc(), top, theme.Current().TextFont)
+		if n := s.nemeses[i]; n != -1 {
+			nemesis := s.entries[n]
+			common.DrawTextCentered(screen, fmt.Sprintf(rowFmt, blankName, util.PadRight(nemesis.Name, model.MaxNameLength), blankName), nemesis.ColourFunc(), top, theme.Current().TextFont)
+		}
+		allTimeColour := theme.Current().Info
+		if idx := slices.IndexFunc(s.entries, func(other Entry) bool { return other.Name == e.AllTimeNemesis }); idx != -1 {
+			allTimeColour = s.entries[idx].ColourFunc()
+		}
+		common.DrawTextCentered(screen, fmt.Sprintf(rowFmt, blankName, blankName, util.PadRight(e.AllTimeNemesis, model.MaxNameLength)), allTimeColour, top, theme.Current().TextFont)
+	}
+}
+
+func (s *Scoreboard) drawAwards(screen *ebiten.Image) {
+	if len(s.awards) == 0 {
+		common.DrawTextCentered(screen, "NO AWARDS THIS TIME", theme.Current().Info, float64(common.ScoreHeight()*2*3), theme.Current().TextFont)
+		return
+	}
+	for i, a := range s.awards {
+		e := s.entries[a.Winner]
+		common.DrawTextCentered(
+			screen,
+			fmt.Sprintf("%-14s %s %4d", a.Title, util.PadRight(e.Name, model.MaxNameLength), a.Value),
+			e.ColourFunc(),
+			float64(common.ScoreHeight()*2*(i+3)),
+			theme.Current().TextFont,
 		)
 	}
 }
@@ -3,25 +3,74 @@ package scoreboard
 import (
 	"image/color"
 	"slices"
+	"snakehem/game/shared/stats"
 )
 
 type Entry struct {
-	Name       string
-	Score      int
-	ColourFunc func() color.Color
+	SnakeId int
+	Name    string
+	Score   int
+	Stats   stats.Stats
+	// AllTimeNemesis is the name of the player's nemesis over all recorded matches, empty if there's none
+	AllTimeNemesis string
+	ColourFunc     func() color.Color
 }
 
+// Page is one of the screens of the scoreboard, flipped through with left and right.
+type Page uint8
+
+const (
+	Scores Page = iota
+	Fighting
+	Nipping
+	Moving
+	Rivalry
+	Nemeses
+	Awards
+	pageCount
+)
+
+var pageTitles = []string{"SCORES", "FIGHTING", "NIPPING", "MOVING", "RIVALRY", "NEMESES", "AWARDS"}
+
 type Scoreboard struct {
 	entries []Entry
+	awards  []stats.Award
+	// nemeses are the indices of the entries who have hurt each entry the most during the match, -1 for none
+	nemeses []int
+	page    Page
 }
 
 func NewScoreboard(entries []Entry) *Scoreboard {
 	sortedEntries := make([]Entry, len(entries))
 	copy(sortedEntries, entries)
-	slices.SortFunc(sortedEntries, func(a, b Entry) int {
+	slices.SortStableFunc(sortedEntries, func(a, b Entry) int {
 		return b.Score - a.Score
 	})
+	entryStats := make([]stats.Stats, len(sortedEntries))
+	byId := make([]stats.Stats, len(sortedEntries))
+	for i, e := range sortedEntries {
+		entryStats[i] = e.Stats
+		byId[e.SnakeId] = e.Stats
+	}
+	nemeses := make([]int, len(sortedEntries))
+	for i, e := range sortedEntries {
+		nemeses[i] = -1
+		if nemesisId, ok := stats.Nemesis(byId, e.SnakeId); ok {
+			nemeses[i] = slices.IndexFunc(sortedEntries, func(other Entry) bool { return other.SnakeId == nemesisId })
+		}
+	}
 	return &Scoreboard{
 		entries: sortedEntries,
+		awards:  stats.Awards(entryStats),
+		nemeses: nemeses,
+		page:    Scores,
 	}
 }
+
+func (s *Scoreboard) NextPage() {
+	s.page = (s.page + 1) % pageCount
+}
+
+func (s *Scoreboard) PreviousPage() {
+	s.page = (s.page + pageCount - 1) % pageCount
+}
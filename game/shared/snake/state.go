@@ -3,6 +3,7 @@ package snake
 import (
 	"image/color"
 	"math"
+	"snakehem/input/haptics"
 	"snakehem/model"
 )
 
@@ -13,6 +14,10 @@ type Snake struct {
 	Colour    color.Color
 	Direction Direction
 	Score     int
+	// Rating is the skill rating of the player, rounded, shown in the lobby
+	Rating int
+	// Rumble is how strongly the controller of the player vibrates, also shown in the lobby
+	Rumble haptics.Intensity
 }
 
 type Link struct {
@@ -20,7 +25,10 @@ type Link struct {
 	HealthPercent int8
 	X             int
 	Y             int
-	Redness       float32
+	// PrevX and PrevY are the coordinates before the last step, used to draw the movement smoothly
+	PrevX   int
+	PrevY   int
+	Redness float32
 }
 
 func NewSnake(id int, name string, colour color.Color) *Snake {
@@ -30,6 +38,7 @@ func NewSnake(id int, name string, colour color.Color) *Snake {
 		Colour: colour,
 		Score:  0,
 		Name:   name,
+		Rumble: haptics.High,
 	}
 	snake.Links[0] = &Link{
 		HealthPercent: 100,
@@ -61,6 +70,12 @@ func (s *Snake) PickInitialDirection() {
 	s.Direction = dir
 }
 
+// RememberPosition is called before each step, so that a link which doesn't move keeps PrevX and PrevY equal to X and Y.
+func (l *Link) RememberPosition() {
+	l.PrevX = l.X
+	l.PrevY = l.Y
+}
+
 func (l *Link) ChangeRedness(delta float32) {
 	l.Redness += delta / model.TpsMultiplier
 	if l.Redness < 0 {
@@ -80,6 +95,12 @@ const (
 	Right
 )
 
+var directionNames = []string{"None", "Up", "Down", "Left", "Right"}
+
+func (d Direction) String() string {
+	return directionNames[d]
+}
+
 func (d Direction) Dx() int {
 	if d == Left {
 		return -1
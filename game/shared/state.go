@@ -4,36 +4,65 @@ import (
 	"image/color"
 	"math"
 	"math/rand/v2"
+	"slices"
 	"snakehem/game/common"
+	"snakehem/game/shared/event"
+	"snakehem/game/shared/pausemenu"
 	"snakehem/game/shared/scoreboard"
 	"snakehem/game/shared/snake"
+	"snakehem/game/shared/stats"
 	"snakehem/model"
+	"snakehem/tournament"
 	"snakehem/util"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
 type Content struct {
-	Stage            Stage
-	Grid             [model.GridSize][model.GridSize]any
+	Stage Stage
+	// Grid holds the snake links by their coordinates, nil for empty cells
+	Grid             [model.GridSize][model.GridSize]*snake.Link
 	Snakes           []*snake.Snake
 	Countdown        int
 	FadeCountdown    int
 	ActionFrameCount uint64
-	scoreboard       *scoreboard.Scoreboard
-	applePos         *util.Coords
+	// Smooth makes snakes slide between cells instead of jumping, see drawItems
+	Smooth bool
+	// Pause is the menu shown while the action is paused, nil when it's not
+	Pause           *pausemenu.PauseMenu
+	ResumeCountdown int
+	// Events are emitted as the action goes on, stamped with ActionFrameCount
+	Events *event.Bus
+	// Stats are gathered from the events and the steps of the snakes, and shown on the scoreboard
+	Stats *stats.Tracker
+	// Tournament is the tournament being played, nil outside the tournament mode
+	Tournament *tournament.Tournament
+	scoreboard *scoreboard.Scoreboard
+	applePos   *util.Coords
+	cells      *cellBatch
 }
 
 func NewContent() *Content {
-	return &Content{
+	c := &Content{
 		Stage:            Lobby,
-		Grid:             [model.GridSize][model.GridSize]any{},
+		Grid:             [model.GridSize][model.GridSize]*snake.Link{},
 		Countdown:        model.Tps * model.CountdownSeconds,
 		FadeCountdown:    0,
 		ActionFrameCount: 0,
+		Smooth:           false,
+		Pause:            nil,
+		ResumeCountdown:  0,
+		Events:           nil,
+		Stats:            nil,
+		Tournament:       nil,
 		scoreboard:       nil,
 		applePos:         nil,
+		cells:            newCellBatch(),
 	}
+	c.Events = event.NewBus(func() uint64 { return c.ActionFrameCount })
+	c.Stats = stats.NewTracker(c.Events)
+	return c
 }
 
 type Stage uint8
@@ -42,9 +71,14 @@ const (
 	Lobby Stage = iota
 	Action
 	Scoreboard
+	// Bracket shows the tournament between the heats
+	Bracket
 )
 
-func (c *Content) SwitchToScoreboardStage() {
+// SwitchToScoreboardStage ends the match. MatchEnded is emitted first, so that allTimeNemesis can take
+// the match just played into account.
+func (c *Content) SwitchToScoreboardStage(allTimeNemesis func(name string) (string, bool)) {
+	c.Events.Emit(event.MatchEnded{Scores: c.scores(), Aborted: false})
 	c.Stage = Scoreboard
 	entries := make([]scoreboard.Entry, len(c.Snakes))
 	for i, s := range c.Snakes {
@@ -52,20 +86,55 @@ func (c *Content) SwitchToScoreboardStage() {
 		if score > model.TargetScore {
 			score = model.TargetScore
 		}
+		nemesis, _ := allTimeNemesis(s.Name)
 		entries[i] = scoreboard.Entry{
-			Name:  s.Name,
-			Score: score,
+			SnakeId:        s.Id,
+			Name:           s.Name,
+			Score:          score,
+			Stats:          c.Stats.Of(s.Id),
+			AllTimeNemesis: nemesis,
 			ColourFunc: func() color.Color {
-				return common.WithRedness(s.Colour, s.Links[0].Redness)
+				return common.LinkColour(s.Colour, s.Links[0].Redness)
 			},
 		}
 	}
 	c.scoreboard = scoreboard.NewScoreboard(entries)
 }
 
+// ToggleCellBatching switches between drawing the grid in one call and drawing every cell on its own,
+// and tells which of the two is on.
+func (c *Content) ToggleCellBatching() bool {
+	c.cells.unbatched = !c.cells.unbatched
+	return !c.cells.unbatched
+}
+
+// FlipScoreboardPage goes to the next or previous page of stats. It does nothing once
+// the scoreboard is gone, as it is when another player has just pressed start.
+func (c *Content) FlipScoreboardPage(forward bool) {
+	if c.scoreboard == nil {
+		return
+	}
+	if forward {
+		c.scoreboard.NextPage()
+	} else {
+		c.scoreboard.PreviousPage()
+	}
+}
+
+func (c *Content) scores() []int {
+	scores := make([]int, len(c.Snakes))
+	for i, s := range c.Snakes {
+		scores[i] = min(s.Score, model.TargetScore)
+	}
+	return scores
+}
+
 func (c *Content) SwitchToLobbyStage() {
+	if c.Stage == Action {
+		c.Events.Emit(event.MatchEnded{Scores: c.scores(), Aborted: true})
+	}
 	c.Stage = Lobby
-	c.Grid = [model.GridSize][model.GridSize]any{}
+	c.Grid = [model.GridSize][model.GridSize]*snake.Link{}
 	for _, s := range c.Snakes {
 		s.Score = 0
 		s.Links = s.Links[0:1]
@@ -73,12 +142,63 @@ func (c *Content) SwitchToLobbyStage() {
 	c.Countdown = model.Tps * model.CountdownSeconds
 	c.FadeCountdown = 0
 	c.ActionFrameCount = 0
+	c.Pause = nil
+	c.ResumeCountdown = 0
 	c.scoreboard = nil
 	c.applePos = nil
 	c.LayoutSnakes()
 	log.Info().Msg("Game restarted")
 }
 
+// SwitchToBracketStage shows the tournament after a heat, resetting everything like SwitchToLobbyStage does.
+func (c *Content) SwitchToBracketStage() {
+	c.SwitchToLobbyStage()
+	c.Stage = Bracket
+}
+
+// HeatLineup compares the joined players with the tournament heat to be played next, telling who's missing
+// and who isn't in the heat. Both are empty when there's no heat to play.
+func (c *Content) HeatLineup() (missing, extra []string) {
+	heat := c.CurrentHeat()
+	if heat == nil {
+		return nil, nil
+	}
+	for _, name := range heat.Players {
+		if !slices.ContainsFunc(c.Snakes, func(s *snake.Snake) bool { return strings.EqualFold(s.Name, name) }) {
+			missing = append(missing, name)
+		}
+	}
+	for _, s := range c.Snakes {
+		if !heat.Has(s.Name) {
+			extra = append(extra, s.Name)
+		}
+	}
+	return missing, extra
+}
+
+// CurrentHeat is the tournament heat to be played next, nil outside the tournament mode or once it's over.
+func (c *Content) CurrentHeat() *tournament.Heat {
+	if c.Tournament == nil {
+		return nil
+	}
+	heat, _ := c.Tournament.CurrentHeat()
+	return heat
+}
+
+// CanStart tells if the action can start: there have to be two players at least, and in the tournament
+// mode they have to be the players of the next heat.
+func (c *Content) CanStart() bool {
+	missing, extra := c.HeatLineup()
+	return len(c.Snakes) > 1 && len(missing) == 0 && len(extra) == 0
+}
+
+// CanForfeit tells if the tournament heat can go on without the players who haven't shown up, who forfeit it.
+// Someone of the heat has to be there, and nobody else.
+func (c *Content) CanForfeit() bool {
+	missing, extra := c.HeatLineup()
+	return len(c.Snakes) > 0 && len(missing) > 0 && len(extra) == 0
+}
+
 func (c *Content) LayoutSnakes() {
 	delta := 2 * math.Pi / float64(len(c.Snakes))
 	alpha := float64(0)
@@ -88,6 +208,7 @@ func (c *Content) LayoutSnakes() {
 		head := s.Links[0]
 		head.X = x
 		head.Y = y
+		head.RememberPosition()
 		c.Grid[y][x] = head
 		alpha += delta
 		s.PickInitialDirection()
@@ -99,34 +220,56 @@ func (c *Content) TryToPutNewApple() {
 		x, y := c.randomUnoccupiedCell()
 		if x != -1 && y != -1 {
 			c.applePos = &util.Coords{X: x, Y: y}
-			log.Debug().Int("x", x).Int("y", y).Msg("Put a new apple")
+			c.Events.Emit(event.AppleSpawned{X: x, Y: y})
 		}
 	}
 }
 
 func (c *Content) IncScore(snake *snake.Snake, delta int) {
 	snake.Score += delta
-	log.Debug().Int("snakeId", snake.Id).Int("score", snake.Score).Msg("New score")
+	c.Events.Emit(event.ScoreChanged{SnakeId: snake.Id, Delta: delta, Score: snake.Score})
 	if snake.Score >= model.TargetScore {
 		log.Info().Msg("Stopping the action!")
 		c.FadeCountdown = model.GridFadeCountdown
 	}
 }
 
+// IsApproachingTarget tells if a score is close enough to the target to reach it with a single nip.
+func IsApproachingTarget(score int) bool {
+	return score+model.ApproachingTargetScoreGap >= model.TargetScore
+}
+
 func (c *Content) IsAppleHere(x, y int) bool {
 	return c.applePos != nil && *c.applePos == util.Coords{X: x, Y: y}
 }
 
 func (c *Content) EatApple(snake *snake.Snake) {
+	c.Events.Emit(event.AppleEaten{SnakeId: snake.Id, X: c.applePos.X, Y: c.applePos.Y})
 	c.applePos = nil
 	c.IncScore(snake, model.AppleScore)
-	log.Debug().Int("snakeId", snake.Id).Msg("Apple eaten!")
 }
 
 func (c *Content) GetCountdownSeconds() int {
 	return (c.Countdown - 1) / model.Tps
 }
 
+func (c *Content) PauseAction() {
+	c.Pause = pausemenu.NewPauseMenu()
+	log.Info().Msg("Action paused")
+}
+
+// ResumeAction closes the pause menu and starts a short countdown, after which the action goes on.
+func (c *Content) ResumeAction() {
+	c.Pause = nil
+	c.ResumeCountdown = model.Tps * model.ResumeCountdownSeconds
+	log.Info().Msg("Action resumed")
+}
+
+// GetResumeCountdownSeconds rounds up, unlike GetCountdownSeconds, because there's no "GO!" when resuming.
+func (c *Content) GetResumeCountdownSeconds() int {
+	return (c.ResumeCountdown + model.Tps - 1) / model.Tps
+}
+
 func (c *Content) randomUnoccupiedCell() (int, int) {
 	x := rand.IntN(model.GridSize)
 	y := rand.IntN(model.GridSize)
@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"snakehem/game/shared/event"
+	"snakehem/model"
+)
+
+// Stats are what a player has done during a match, besides scoring.
+type Stats struct {
+	BitesDealt    int
+	BitesReceived int
+	SelfBites     int
+	TailsNipped   int
+	// LinksTaken counts the links of the tails nipped off other snakes
+	LinksTaken    int
+	ApplesEaten   int
+	LongestLength int
+	// TicksAtFullLength is the time spent at model.SnakeTargetLength
+	TicksAtFullLength int
+	// Distance is the number of cells moved
+	Distance int
+	// BitesOn and NipsOn are what has been dealt to each other snake, indexed by snake id
+	BitesOn []int
+	NipsOn  []int
+}
+
+func (s Stats) SecondsAtFullLength() int {
+	return s.TicksAtFullLength / model.Tps
+}
+
+// Tracker gathers the stats of every snake from the events of the action, and from the steps the snakes make.
+type Tracker struct {
+	stats []Stats
+}
+
+func NewTracker(bus *event.Bus) *Tracker {
+	t := &Tracker{
+		stats: nil,
+	}
+	event.Subscribe(bus, func(_ uint64, e event.MatchStarted) {
+		t.stats = make([]Stats, len(e.Players))
+		for i := range t.stats {
+			t.stats[i].BitesOn = make([]int, len(e.Players))
+			t.stats[i].NipsOn = make([]int, len(e.Players))
+		}
+	})
+	event.Subscribe(bus, func(_ uint64, e event.LinkBitten) {
+		if e.SnakeId == e.TargetSnakeId {
+			t.stats[e.SnakeId].SelfBites++
+			return
+		}
+		t.stats[e.SnakeId].BitesDealt++
+		t.stats[e.SnakeId].BitesOn[e.TargetSnakeId]++
+		t.stats[e.TargetSnakeId].BitesReceived++
+	})
+	event.Subscribe(bus, func(_ uint64, e event.TailNipped) {
+		if e.SnakeId == e.TargetSnakeId {
+			return
+		}
+		t.stats[e.SnakeId].TailsNipped++
+		t.stats[e.SnakeId].NipsOn[e.TargetSnakeId]++
+		t.stats[e.SnakeId].LinksTaken += e.Length
+	})
+	event.Subscribe(bus, func(_ uint64, e event.AppleEaten) {
+		t.stats[e.SnakeId].ApplesEaten++
+	})
+	return t
+}
+
+// RecordStep is called for every snake on every step of the action, whether it could move or not.
+func (t *Tracker) RecordStep(snakeId, length int, moved bool) {
+	s := &t.stats[snakeId]
+	if moved {
+		s.Distance++
+	}
+	s.LongestLength = max(s.LongestLength, length)
+	if length >= model.SnakeTargetLength {
+		s.TicksAtFullLength += model.TpsMultiplier
+	}
+}
+
+// Of returns zero stats for a snake which hasn't been in a match yet.
+func (t *Tracker) Of(snakeId int) Stats {
+	if snakeId >= len(t.stats) {
+		return Stats{}
+	}
+	return t.stats[snakeId]
+}
+
+// Nemesis finds who has hurt a snake the most: nips count first, then bites. The stats are indexed
+// by snake id, and false is returned when nobody else has even bitten the snake.
+func Nemesis(stats []Stats, snakeId int) (int, bool) {
+	nemesis := -1
+	for i, s := range stats {
+		if i == snakeId || snakeId >= len(s.BitesOn) || s.BitesOn[snakeId] == 0 {
+			continue
+		}
+		if nemesis == -1 || s.NipsOn[snakeId] > stats[nemesis].NipsOn[snakeId] ||
+			s.NipsOn[snakeId] == stats[nemesis].NipsOn[snakeId] && s.BitesOn[snakeId] > stats[nemesis].BitesOn[snakeId] {
+			nemesis = i
+		}
+	}
+	return nemesis, nemesis != -1
+}
+
+type Award struct {
+	Title string
+	// Winner is the index of the awarded player in the stats given to Awards
+	Winner int
+	Value  int
+}
+
+var awards = []struct {
+	title string
+	value func(Stats) int
+}{
+	{"MOST VICIOUS", func(s Stats) int { return s.BitesDealt }},
+	{"TAIL COLLECTOR", func(s Stats) int { return s.LinksTaken }},
+	{"APPLE HOARDER", func(s Stats) int { return s.ApplesEaten }},
+	{"PUNCHBAG", func(s Stats) int { return s.BitesReceived }},
+	{"MARATHONER", func(s Stats) int { return s.Distance }},
+	{"FULL FIGURED", func(s Stats) int { return s.SecondsAtFullLength() }},
+	{"OUROBOROS", func(s Stats) int { return s.SelfBites }},
+}
+
+// Awards picks the leader of each category. A tie goes to the player who comes first,
+// and a category nobody has scored in isn't awarded.
+func Awards(stats []Stats) []Award {
+	var result []Award
+	for _, a := range awards {
+		best := Award{Title: a.title, Winner: -1, Value: 0}
+		for i, s := range stats {
+			if v := a.value(s); v > best.Value {
+				best.Winner = i
+				best.Value = v
+			}
+		}
+		if best.Winner != -1 {
+			result = append(result, best)
+		}
+	}
+	return result
+}
@@ -1,9 +1,53 @@
 package unshaded
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"image/color"
+	"snakehem/input/touch"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	dPadArrowOffsetPx = touch.DPadRadiusPx * 0.6
+	dPadArrowSizePx   = touch.DPadRadiusPx * 0.25
+)
+
+var (
+	dPadColour     = color.NRGBA{R: 255, G: 255, B: 255, A: 60}
+	dPadHeldColour = color.NRGBA{R: 255, G: 255, B: 255, A: 160}
+)
 
 func (c *Content) Draw(screen *ebiten.Image) {
-	if c.perfTracker != nil {
-		c.perfTracker.Draw(screen)
+	if touch.IsUsed() {
+		drawTouchDPads(screen)
+	}
+	if c.perfShown {
+		c.perfTracker.Draw(screen, c.perfStats)
+	}
+}
+
+func drawTouchDPads(screen *ebiten.Image) {
+	for r := touch.Region(0); r < touch.RegionCount; r++ {
+		x, y := touch.DPadCentre(r)
+		cx := float32(x)
+		cy := float32(y)
+		vector.StrokeCircle(screen, cx, cy, touch.DPadRadiusPx, 2, dPadColour, true)
+		heldDx, heldDy := touch.HeldDPadDirection(r)
+		for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+			colour := dPadColour
+			if d[0] == heldDx && d[1] == heldDy {
+				colour = dPadHeldColour
+			}
+			vector.FillRect(
+				screen,
+				cx+float32(d[0])*dPadArrowOffsetPx-dPadArrowSizePx/2,
+				cy+float32(d[1])*dPadArrowOffsetPx-dPadArrowSizePx/2,
+				dPadArrowSizePx,
+				dPadArrowSizePx,
+				colour,
+				false,
+			)
+		}
 	}
 }
@@ -3,18 +3,25 @@ package perftracker
 import (
 	"snakehem/assets/adhoc8"
 	"snakehem/game/common"
+	"snakehem/model"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	"golang.org/x/image/colornames"
 )
 
 const (
 	textX   = 15
 	padding = 4
+	// graphBarWidthPx is the width of a frame on the graph, and graphHeightPx stands for tickBudget
+	graphBarWidthPx = 2
+	graphHeightPx   = 48
+	tickBudget      = time.Second / model.Tps
 )
 
-func (p *PerfTracker) Draw(screen *ebiten.Image) {
-	stats := p.GetStats()
+// Draw shows the stats last taken with GetStats, under a graph of the draw times of the last frames.
+func (p *PerfTracker) Draw(screen *ebiten.Image, stats PerfStats) {
 	if stats.SampleCount < 10 {
 		// Wait for enough samples before displaying
 		return
@@ -22,6 +29,7 @@ func (p *PerfTracker) Draw(screen *ebiten.Image) {
 	lines := stats.AsString()
 	var lineSpacing = common.Adhoc8Height
 	y := screen.Bounds().Dy() - len(lines)*lineSpacing - 2*padding
+	p.drawGraph(screen, y-padding)
 	// First line (TPS/FPS) always in light grey
 	adhoc8.Font.DrawString(screen, textX, y, lines[0], colornames.Lightgrey)
 	y += lineSpacing
@@ -38,4 +46,29 @@ func (p *PerfTracker) Draw(screen *ebiten.Image) {
 		drawColor = colornames.Red
 	}
 	adhoc8.Font.DrawString(screen, textX, y, lines[2], drawColor)
+	y += lineSpacing
+	// GC line always in light grey
+	adhoc8.Font.DrawString(screen, textX, y, lines[3], colornames.Lightgrey)
+	// Draw line of the previous variant, if any, for comparison
+	if len(lines) > 4 {
+		y += lineSpacing
+		adhoc8.Font.DrawString(screen, textX, y, lines[4], colornames.Darkgrey)
+	}
+}
+
+// drawGraph puts a bar for every recent frame above a given line, as tall as drawing the frame took. The graph
+// is as tall as a tick lasts, and the frames which took longer are red.
+func (p *PerfTracker) drawGraph(screen *ebiten.Image, bottom int) {
+	scale := float32(graphHeightPx) / float32(tickBudget)
+	base := float32(bottom)
+	for i, drawTime := range p.drawTimes {
+		height := min(float32(drawTime)*scale, graphHeightPx)
+		colour := colornames.Lightgrey
+		if drawTime > tickBudget {
+			colour = colornames.Red
+		}
+		vector.FillRect(screen, float32(textX+i*graphBarWidthPx), base-height, graphBarWidthPx, height, colour, false)
+	}
+	vector.FillRect(screen, textX, base-graphHeightPx, graphSize*graphBarWidthPx, 1, colornames.Darkgrey, false)
+	vector.FillRect(screen, textX, base, graphSize*graphBarWidthPx, 1, colornames.Darkgrey, false)
 }
@@ -0,0 +1,42 @@
+package perftracker
+
+import (
+	"os"
+	"snakehem/diagnostics"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/rs/zerolog/log"
+)
+
+// dumpProfileDuration is how long the CPU profile of a dump runs for
+const dumpProfileDuration = 5 * time.Second
+
+// Dump writes the update and draw histograms collected since the tracker was started to files in a given
+// directory, as percentile distributions in milliseconds which the HdrHistogram plotter reads. It also
+// starts a CPU profile, which is written once dumpProfileDuration has passed, for go tool pprof.
+func (p *PerfTracker) Dump(dir string) {
+	prefix, err := diagnostics.Prefix(dir, "perf")
+	if err != nil {
+		log.Error().Err(err).Str("dir", dir).Msg("Failed to create the performance dump directory")
+		return
+	}
+	writeHistogram(prefix+"-update.hgrm", p.updateTotal)
+	writeHistogram(prefix+"-draw.hgrm", p.drawTotal)
+	diagnostics.Capture(prefix, dumpProfileDuration, false)
+}
+
+func writeHistogram(path string, hist *hdrhistogram.Histogram) {
+	f, err := os.Create(path)
+	if err == nil {
+		_, err = hist.PercentilesPrint(f, 5, float64(time.Millisecond/time.Microsecond))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to write the histogram")
+		return
+	}
+	log.Info().Str("path", path).Int64("count", hist.TotalCount()).Msg("Histogram written")
+}
@@ -0,0 +1,105 @@
+package perftracker
+
+import (
+	"math"
+	runtimemetrics "runtime/metrics"
+	"slices"
+	"time"
+)
+
+const (
+	gcPausesMetric = "/sched/pauses/total/gc:seconds"
+	gcCyclesMetric = "/gc/cycles/total:gc-cycles"
+	heapMetric     = "/memory/classes/heap/objects:bytes"
+	heapGoalMetric = "/gc/heap/goal:bytes"
+)
+
+// memoryTracker follows the garbage collector over the same window as the histograms. The runtime
+// only keeps totals since the start, so one sample is kept per second and the oldest is taken away
+// from the newest. Reading runtime/metrics doesn't stop the world, unlike runtime.ReadMemStats.
+type memoryTracker struct {
+	samples []runtimemetrics.Sample
+	// pauseCounts and cycles are the totals of the last seconds, oldest first
+	pauseCounts [][]uint64
+	cycles      []uint64
+}
+
+func newMemoryTracker() *memoryTracker {
+	return &memoryTracker{
+		samples: []runtimemetrics.Sample{
+			{Name: gcPausesMetric},
+			{Name: gcCyclesMetric},
+			{Name: heapMetric},
+			{Name: heapGoalMetric},
+		},
+		pauseCounts: make([][]uint64, 0, windowSeconds+1),
+		cycles:      make([]uint64, 0, windowSeconds+1),
+	}
+}
+
+type memoryStats struct {
+	GCPauseP50 time.Duration
+	GCPauseP99 time.Duration
+	GCPauseMax time.Duration
+	// GCCycles is the number of collections per second
+	GCCycles  float64
+	HeapBytes uint64
+	HeapGoal  uint64
+}
+
+// Sample is called once a second.
+func (m *memoryTracker) Sample() memoryStats {
+	runtimemetrics.Read(m.samples)
+	pauses := m.samples[0].Value.Float64Histogram()
+	m.pauseCounts = appendToWindow(m.pauseCounts, slices.Clone(pauses.Counts))
+	m.cycles = appendToWindow(m.cycles, m.samples[1].Value.Uint64())
+	stats := memoryStats{
+		HeapBytes: m.samples[2].Value.Uint64(),
+		HeapGoal:  m.samples[3].Value.Uint64(),
+	}
+	if len(m.cycles) < 2 {
+		return stats
+	}
+	seconds := float64(len(m.cycles) - 1)
+	stats.GCCycles = float64(m.cycles[len(m.cycles)-1]-m.cycles[0]) / seconds
+	oldest := m.pauseCounts[0]
+	newest := m.pauseCounts[len(m.pauseCounts)-1]
+	counts := make([]uint64, len(newest))
+	var total uint64
+	for i := range newest {
+		counts[i] = newest[i] - oldest[i]
+		total += counts[i]
+	}
+	if total > 0 {
+		stats.GCPauseP50 = bucketAtQuantile(pauses.Buckets, counts, total, 0.5)
+		stats.GCPauseP99 = bucketAtQuantile(pauses.Buckets, counts, total, 0.99)
+		stats.GCPauseMax = bucketAtQuantile(pauses.Buckets, counts, total, 1)
+	}
+	return stats
+}
+
+// appendToWindow keeps the samples of the last windowSeconds, plus the one they're measured from.
+func appendToWindow[T any](window []T, sample T) []T {
+	if len(window) > windowSeconds {
+		window = slices.Delete(window, 0, 1)
+	}
+	return append(window, sample)
+}
+
+// bucketAtQuantile finds the bucket the quantile falls in, and returns its upper bound, or its lower
+// bound for the last bucket, which is unbounded.
+func bucketAtQuantile(buckets []float64, counts []uint64, total uint64, q float64) time.Duration {
+	target := uint64(math.Ceil(q * float64(total)))
+	var cumulative uint64
+	for i, c := range counts {
+		cumulative += c
+		if cumulative >= target && c > 0 {
+			bound := buckets[i+1]
+			if math.IsInf(bound, 1) {
+				bound = buckets[i]
+			}
+			return time.Duration(bound * float64(time.Second))
+		}
+	}
+	return 0
+}
@@ -2,6 +2,8 @@ package perftracker
 
 import (
 	"fmt"
+	"slices"
+	"snakehem/metrics"
 	"snakehem/model"
 	"time"
 
@@ -10,8 +12,15 @@ import (
 )
 
 const (
+	// windowSeconds is how far back the percentiles, averages and GC stats go
+	windowSeconds = 5
 	// WindowSize is the number of TPS/FPS samples to keep for rolling averages
-	windowSize = 5 * model.Tps // 5 seconds at 60 TPS
+	windowSize = windowSeconds * model.Tps
+	// refreshTicks is how often the percentiles of the window are worked out, as merging
+	// the histograms of every second is too slow to do on every tick
+	refreshTicks = model.Tps / 10
+	// graphSize is the number of frames shown on the frame time graph
+	graphSize = 3 * model.Tps
 
 	// Histogram parameters
 	minMicros = 1       // 1 microsecond minimum
@@ -31,34 +40,81 @@ type percentileSnapshot struct {
 }
 
 type PerfTracker struct {
-	updateHist           *hdrhistogram.Histogram
-	drawHist             *hdrhistogram.Histogram
+	// updateWindow and drawWindow hold a histogram per second of the window
+	updateWindow *hdrhistogram.WindowedHistogram
+	drawWindow   *hdrhistogram.WindowedHistogram
+	// updateTotal and drawTotal hold every duration since the tracker was started, for the metrics and dumps
+	updateTotal          *hdrhistogram.Histogram
+	drawTotal            *hdrhistogram.Histogram
+	updateP              percentileSnapshot
+	drawP                percentileSnapshot
 	tpsSamples           []float64
 	fpsSamples           []float64
 	updateBaselineP      percentileSnapshot
 	drawBaselineP        percentileSnapshot
 	ticksSinceLastUpdate int
+	ticks                int
+	memory               *memoryTracker
+	memoryStats          memoryStats
+	// drawTimes are how long drawing the last frames took, oldest first
+	drawTimes []time.Duration
+	// drawVariant names the way of drawing being measured, empty if it has never been switched
+	drawVariant         string
+	previousDrawVariant string
+	previousDrawP       percentileSnapshot
 }
 
 func NewPerfTracker() *PerfTracker {
 	return &PerfTracker{
-		updateHist: hdrhistogram.New(minMicros, maxMicros, sigFigs),
-		drawHist:   hdrhistogram.New(minMicros, maxMicros, sigFigs),
-		tpsSamples: make([]float64, 0, windowSize),
-		fpsSamples: make([]float64, 0, windowSize),
+		updateWindow: hdrhistogram.NewWindowed(windowSeconds, minMicros, maxMicros, sigFigs),
+		drawWindow:   hdrhistogram.NewWindowed(windowSeconds, minMicros, maxMicros, sigFigs),
+		updateTotal:  hdrhistogram.New(minMicros, maxMicros, sigFigs),
+		drawTotal:    hdrhistogram.New(minMicros, maxMicros, sigFigs),
+		tpsSamples:   make([]float64, 0, windowSize),
+		fpsSamples:   make([]float64, 0, windowSize),
+		memory:       newMemoryTracker(),
+		drawTimes:    make([]time.Duration, 0, graphSize),
 	}
 }
 
 func (p *PerfTracker) RecordUpdate(duration time.Duration) {
-	if err := p.updateHist.RecordValue(duration.Microseconds()); err != nil {
+	if err := p.updateWindow.Current.RecordValue(duration.Microseconds()); err != nil {
 		log.Warn().Dur("duration", duration).Msg("Update took incredibly long")
+		return
 	}
+	_ = p.updateTotal.RecordValue(duration.Microseconds())
 }
 
 func (p *PerfTracker) RecordDraw(duration time.Duration) {
-	if err := p.drawHist.RecordValue(duration.Microseconds()); err != nil {
+	p.drawTimes = appendToGraph(p.drawTimes, duration)
+	if err := p.drawWindow.Current.RecordValue(duration.Microseconds()); err != nil {
 		log.Warn().Dur("duration", duration).Msg("Draw took incredibly long")
+		return
 	}
+	_ = p.drawTotal.RecordValue(duration.Microseconds())
+}
+
+func appendToGraph(samples []time.Duration, sample time.Duration) []time.Duration {
+	if len(samples) == graphSize {
+		samples = slices.Delete(samples, 0, 1)
+	}
+	return append(samples, sample)
+}
+
+// SetDrawVariant restarts the draw time measurements, keeping the percentiles of the previous variant
+// for comparison.
+func (p *PerfTracker) SetDrawVariant(name string) {
+	if merged := p.drawWindow.Merge(); merged.TotalCount() > 0 {
+		p.previousDrawVariant = p.drawVariant
+		if p.previousDrawVariant == "" {
+			p.previousDrawVariant = "before"
+		}
+		p.previousDrawP = snapshot(merged)
+	}
+	p.drawVariant = name
+	p.drawWindow = hdrhistogram.NewWindowed(windowSeconds, minMicros, maxMicros, sigFigs)
+	p.drawP = percentileSnapshot{}
+	p.drawBaselineP = percentileSnapshot{}
 }
 
 func (p *PerfTracker) RecordTPS(tps float64) {
@@ -76,55 +132,68 @@ func (p *PerfTracker) RecordFPS(fps float64) {
 }
 
 type PerfStats struct {
-	UpdateP50     time.Duration
-	UpdateP90     time.Duration
-	UpdateP95     time.Duration
-	UpdateP99     time.Duration
-	DrawP50       time.Duration
-	DrawP90       time.Duration
-	DrawP95       time.Duration
-	DrawP99       time.Duration
-	TPSAvg        float64
-	FPSAvg        float64
-	SampleCount   int64
-	UpdateWarning bool // True if Update percentiles are growing too fast
-	DrawWarning   bool // True if Draw percentiles are growing too fast
-}
-
-//goland:noinspection DuplicatedCode
+	UpdateP50   time.Duration
+	UpdateP90   time.Duration
+	UpdateP95   time.Duration
+	UpdateP99   time.Duration
+	DrawP50     time.Duration
+	DrawP90     time.Duration
+	DrawP95     time.Duration
+	DrawP99     time.Duration
+	DrawVariant string
+	// PreviousDraw are the draw percentiles of the previous variant, if it has been switched
+	PreviousDrawVariant string
+	PreviousDraw        [4]time.Duration
+	TPSAvg              float64
+	FPSAvg              float64
+	SampleCount         int64
+	UpdateWarning       bool // True if Update percentiles are growing too fast
+	DrawWarning         bool // True if Draw percentiles are growing too fast
+	// GCPauseP50, GCPauseP99 and GCPauseMax are rounded up to the buckets the runtime counts pauses in
+	GCPauseP50 time.Duration
+	GCPauseP99 time.Duration
+	GCPauseMax time.Duration
+	// GCCycles is the number of collections per second
+	GCCycles  float64
+	HeapBytes uint64
+	HeapGoal  uint64
+}
+
+// GetStats is called once per tick. The percentiles are of the last windowSeconds, so that
+// they follow the changes in performance instead of flattening out over time.
 func (p *PerfTracker) GetStats() PerfStats {
-	stats := PerfStats{
-		SampleCount: p.updateHist.TotalCount(),
+	if p.ticks%refreshTicks == 0 {
+		p.updateP = snapshot(p.updateWindow.Merge())
+		p.drawP = snapshot(p.drawWindow.Merge())
 	}
-
-	var currentUpdateSnapshot, currentDrawSnapshot percentileSnapshot
-
-	if p.updateHist.TotalCount() > 0 {
-		stats.UpdateP50 = time.Duration(p.updateHist.Mean()) * time.Microsecond
-		stats.UpdateP90 = time.Duration(p.updateHist.ValueAtQuantile(90)) * time.Microsecond
-		stats.UpdateP95 = time.Duration(p.updateHist.ValueAtQuantile(95)) * time.Microsecond
-		stats.UpdateP99 = time.Duration(p.updateHist.ValueAtQuantile(99)) * time.Microsecond
-
-		currentUpdateSnapshot = percentileSnapshot{
-			p50: int64(p.updateHist.Mean()),
-			p90: p.updateHist.ValueAtQuantile(90),
-			p95: p.updateHist.ValueAtQuantile(95),
-			p99: p.updateHist.ValueAtQuantile(99),
-		}
+	if p.ticks%percentileHistorySize == 0 {
+		p.memoryStats = p.memory.Sample()
 	}
-
-	if p.drawHist.TotalCount() > 0 {
-		stats.DrawP50 = time.Duration(p.drawHist.Mean()) * time.Microsecond
-		stats.DrawP90 = time.Duration(p.drawHist.ValueAtQuantile(90)) * time.Microsecond
-		stats.DrawP95 = time.Duration(p.drawHist.ValueAtQuantile(95)) * time.Microsecond
-		stats.DrawP99 = time.Duration(p.drawHist.ValueAtQuantile(99)) * time.Microsecond
-
-		currentDrawSnapshot = percentileSnapshot{
-			p50: int64(p.drawHist.Mean()),
-			p90: p.drawHist.ValueAtQuantile(90),
-			p95: p.drawHist.ValueAtQuantile(95),
-			p99: p.drawHist.ValueAtQuantile(99),
-		}
+	p.ticks++
+	stats := PerfStats{
+		UpdateP50:           toDuration(p.updateP.p50),
+		UpdateP90:           toDuration(p.updateP.p90),
+		UpdateP95:           toDuration(p.updateP.p95),
+		UpdateP99:           toDuration(p.updateP.p99),
+		DrawP50:             toDuration(p.drawP.p50),
+		DrawP90:             toDuration(p.drawP.p90),
+		DrawP95:             toDuration(p.drawP.p95),
+		DrawP99:             toDuration(p.drawP.p99),
+		SampleCount:         p.updateTotal.TotalCount(),
+		DrawVariant:         p.drawVariant,
+		PreviousDrawVariant: p.previousDrawVariant,
+		PreviousDraw: [4]time.Duration{
+			toDuration(p.previousDrawP.p50),
+			toDuration(p.previousDrawP.p90),
+			toDuration(p.previousDrawP.p95),
+			toDuration(p.previousDrawP.p99),
+		},
+		GCPauseP50: p.memoryStats.GCPauseP50,
+		GCPauseP99: p.memoryStats.GCPauseP99,
+		GCPauseMax: p.memoryStats.GCPauseMax,
+		GCCycles:   p.memoryStats.GCCycles,
+		HeapBytes:  p.memoryStats.HeapBytes,
+		HeapGoal:   p.memoryStats.HeapGoal,
 	}
 
 	if len(p.tpsSamples) > 0 {
@@ -136,34 +205,103 @@ func (p *PerfTracker) GetStats() PerfStats {
 	}
 
 	// Detect rapid growth in percentiles (compare against baseline from 1 second ago)
-	stats.UpdateWarning = p.detectGrowth(p.updateBaselineP, currentUpdateSnapshot)
-	stats.DrawWarning = p.detectGrowth(p.drawBaselineP, currentDrawSnapshot)
+	stats.UpdateWarning = p.detectGrowth(p.updateBaselineP, p.updateP)
+	stats.DrawWarning = p.detectGrowth(p.drawBaselineP, p.drawP)
 
-	// Update baseline snapshot every percentileHistorySize ticks (1 second)
+	// Update baseline snapshot and move the window on every percentileHistorySize ticks (1 second)
 	p.ticksSinceLastUpdate++
 	if p.ticksSinceLastUpdate >= percentileHistorySize {
-		p.updateBaselineP = currentUpdateSnapshot
-		p.drawBaselineP = currentDrawSnapshot
+		p.updateBaselineP = p.updateP
+		p.drawBaselineP = p.drawP
+		p.updateWindow.Rotate()
+		p.drawWindow.Rotate()
 		p.ticksSinceLastUpdate = 0
 	}
 
 	return stats
 }
 
+func toDuration(micros int64) time.Duration {
+	return time.Duration(micros) * time.Microsecond
+}
+
+// Export converts the stats and the histograms collected since the tracker was started for the metrics endpoint.
+func (p *PerfTracker) Export(stats PerfStats) metrics.Perf {
+	return metrics.Perf{
+		UpdateTime:    export(p.updateTotal),
+		DrawTime:      export(p.drawTotal),
+		TPS:           stats.TPSAvg,
+		FPS:           stats.FPSAvg,
+		UpdateGrowing: stats.UpdateWarning,
+		DrawGrowing:   stats.DrawWarning,
+	}
+}
+
+// export counts the recorded durations into the buckets of the metrics. The histogram only tells
+// the range a duration is in, so it's counted in the bucket the top of the range falls in.
+func export(hist *hdrhistogram.Histogram) metrics.Histogram {
+	result := metrics.Histogram{
+		Counts: make([]int64, len(metrics.Bounds)),
+		Count:  hist.TotalCount(),
+		Sum:    time.Duration(hist.Mean()*float64(hist.TotalCount())) * time.Microsecond,
+	}
+	for _, bar := range hist.Distribution() {
+		if bar.Count == 0 {
+			continue
+		}
+		for i, bound := range metrics.Bounds {
+			if bar.To <= bound.Microseconds() {
+				result.Counts[i] += bar.Count
+			}
+		}
+	}
+	return result
+}
+
+func snapshot(hist *hdrhistogram.Histogram) percentileSnapshot {
+	return percentileSnapshot{
+		p50: hist.ValueAtQuantile(50),
+		p90: hist.ValueAtQuantile(90),
+		p95: hist.ValueAtQuantile(95),
+		p99: hist.ValueAtQuantile(99),
+	}
+}
+
 func (s PerfStats) AsString() []string {
-	return []string{
+	drawLabel := "Draw"
+	if s.DrawVariant != "" {
+		drawLabel = fmt.Sprintf("Draw (%s)", s.DrawVariant)
+	}
+	lines := []string{
 		fmt.Sprintf("TPS - avg: %.0f, FPS - avg: %.0f", s.TPSAvg, s.FPSAvg),
 		fmt.Sprintf("Update - P50: %v, P90: %v, P95: %v, P99: %v",
 			formatDuration(s.UpdateP50),
 			formatDuration(s.UpdateP90),
 			formatDuration(s.UpdateP95),
 			formatDuration(s.UpdateP99)),
-		fmt.Sprintf("Draw - P50: %v, P90: %v, P95: %v, P99: %v",
+		fmt.Sprintf("%s - P50: %v, P90: %v, P95: %v, P99: %v",
+			drawLabel,
 			formatDuration(s.DrawP50),
 			formatDuration(s.DrawP90),
 			formatDuration(s.DrawP95),
 			formatDuration(s.DrawP99)),
 	}
+	lines = append(lines, fmt.Sprintf("GC - P50: %v, P99: %v, max: %v, %.1f/s, heap: %s of %s",
+		formatDuration(s.GCPauseP50),
+		formatDuration(s.GCPauseP99),
+		formatDuration(s.GCPauseMax),
+		s.GCCycles,
+		formatBytes(s.HeapBytes),
+		formatBytes(s.HeapGoal)))
+	if s.PreviousDrawVariant != "" {
+		lines = append(lines, fmt.Sprintf("Draw (%s) - P50: %v, P90: %v, P95: %v, P99: %v",
+			s.PreviousDrawVariant,
+			formatDuration(s.PreviousDraw[0]),
+			formatDuration(s.PreviousDraw[1]),
+			formatDuration(s.PreviousDraw[2]),
+			formatDuration(s.PreviousDraw[3])))
+	}
+	return lines
 }
 
 func (p *PerfTracker) detectGrowth(baseline, current percentileSnapshot) bool {
@@ -198,6 +336,10 @@ func avgFloat(values []float64) float64 {
 	return sum / float64(len(values))
 }
 
+func formatBytes(b uint64) string {
+	return fmt.Sprintf("%.1fMB", float64(b)/(1<<20))
+}
+
 func formatDuration(d time.Duration) string {
 	if d == 0 {
 		return "0"
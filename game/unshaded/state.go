@@ -2,17 +2,35 @@ package unshaded
 
 import (
 	"snakehem/game/unshaded/perftracker"
+	"snakehem/metrics"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type Content struct {
+	// perfTracker measures while the overlay is shown, and all the time when the metrics are served
 	perfTracker *perftracker.PerfTracker
+	perfStats   perftracker.PerfStats
+	perfShown   bool
+	// perfDir is where F3 dumps the histograms and a CPU profile, and F1 captures a CPU profile and a trace
+	perfDir string
+	// metrics is nil unless the metrics are served
+	metrics *metrics.Exporter
 }
 
-func NewContent() *Content {
-	return &Content{perfTracker: nil}
+func NewContent(exporter *metrics.Exporter, perfDir string) *Content {
+	c := &Content{
+		perfTracker: nil,
+		perfStats:   perftracker.PerfStats{},
+		perfShown:   false,
+		perfDir:     perfDir,
+		metrics:     exporter,
+	}
+	if exporter != nil {
+		c.perfTracker = perftracker.NewPerfTracker()
+	}
+	return c
 }
 
 type Stage uint8
@@ -30,3 +48,10 @@ func (c *Content) RecordDrawTimeAndFps(since time.Time) {
 		c.perfTracker.RecordFPS(ebiten.ActualFPS())
 	}
 }
+
+// SetDrawVariant starts measuring the draw time anew, keeping the figures of the previous variant for comparison.
+func (c *Content) SetDrawVariant(name string) {
+	if c.perfTracker != nil {
+		c.perfTracker.SetDrawVariant(name)
+	}
+}
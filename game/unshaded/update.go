@@ -1,7 +1,9 @@
 package unshaded
 
 import (
+	"snakehem/diagnostics"
 	"snakehem/game/unshaded/perftracker"
+	"snakehem/model"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -9,12 +11,34 @@ import (
 )
 
 func (c *Content) Update() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) {
+		if prefix, err := diagnostics.Prefix(c.perfDir, "capture"); err != nil {
+			log.Error().Err(err).Str("dir", c.perfDir).Msg("Failed to create the capture directory")
+		} else {
+			diagnostics.Capture(prefix, diagnostics.CaptureDuration, true)
+		}
+	}
 	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
-		if c.perfTracker == nil {
+		c.perfShown = !c.perfShown
+		if c.perfShown && c.perfTracker == nil {
 			c.perfTracker = perftracker.NewPerfTracker()
-		} else {
+		} else if !c.perfShown && c.metrics == nil {
 			c.perfTracker = nil
 		}
-		log.Info().Bool("enabled", c.perfTracker != nil).Msg("Performance tracker")
+		log.Info().Bool("enabled", c.perfShown).Msg("Performance tracker")
+	}
+	if c.perfTracker == nil {
+		if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+			log.Info().Msg("Nothing to dump, F2 starts the performance tracker")
+		}
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		c.perfTracker.Dump(c.perfDir)
+	}
+	c.perfStats = c.perfTracker.GetStats()
+	// the histograms take a while to export, so the metrics are only refreshed once a second
+	if c.metrics != nil && ebiten.Tick()%model.Tps == 0 {
+		c.metrics.SetPerf(c.perfTracker.Export(c.perfStats))
 	}
 }
@@ -1,14 +1,30 @@
 package game
 
 import (
+	"errors"
+	"image/color"
+	"math"
 	"os"
 	"slices"
+	"snakehem/assets/sound"
+	"snakehem/assets/theme"
 	"snakehem/game/common"
 	"snakehem/game/local"
+	"snakehem/game/local/profilepicker"
 	"snakehem/game/shared"
+	"snakehem/game/shared/bracket"
+	"snakehem/game/shared/event"
+	"snakehem/game/shared/pausemenu"
+	"snakehem/game/shared/scoreboard"
 	. "snakehem/game/shared/snake"
 	"snakehem/input"
+	"snakehem/input/controller"
+	"snakehem/input/haptics"
+	"snakehem/input/keyboard"
+	"snakehem/input/pointer"
+	"snakehem/input/touch"
 	"snakehem/model"
+	"snakehem/profile"
 	"strings"
 	"time"
 
@@ -23,23 +39,76 @@ func (g *Game) Update() error {
 		g.unshadedContent.RecordUpdateTimeAndTps(start)
 	}()
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+	// during the action, Escape pauses instead, in the dialogs it goes back, and in the lobby it belongs
+	// to the player on the arrow keys, if any, who quits with Shift+Escape then
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) &&
+		g.sharedContent.Stage != shared.Action &&
+		g.localContent.GetStage() == local.Off &&
+		(!g.isEscapeTaken() || ebiten.IsKeyPressed(ebiten.KeyShift)) {
 		log.Info().Msg("Exiting game")
 		os.Exit(0)
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+		log.Info().Bool("fullscreen", ebiten.IsFullscreen()).Msg("Display mode changed")
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		g.sounds.ToggleMute()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		g.sounds.CycleEffectsVolume()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		g.sounds.CycleMusicVolume()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		g.shaders.NextPreset()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+		g.toggleBufferChurn()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		g.toggleCellBatching()
+	}
+	g.shaders.Update()
+	touch.Update()
+	g.haptics.Update()
+	if g.sharedContent.Pause == nil {
+		g.effectsContent.Update()
+	}
+	g.updateCursorMode()
+	g.updateMusic()
 	g.localContent.Update(&common.Context{Tick: ebiten.Tick()})
 	g.unshadedContent.Update()
+	if g.metrics != nil {
+		g.metrics.SetPlayers(len(g.sharedContent.Snakes))
+	}
 	switch g.sharedContent.Stage {
 	case shared.Lobby:
 		g.updateHeadCount()
 	case shared.Action:
-		if g.sharedContent.GetCountdownSeconds() >= 0 {
+		if g.sharedContent.Pause != nil {
+			g.updatePause()
+			break
+		}
+		if g.sharedContent.ResumeCountdown > 0 {
+			g.updateResumeCountdown()
+			break
+		}
+		if g.sharedContent.FadeCountdown == 0 && g.isExitJustPressed() {
+			g.sharedContent.PauseAction()
+			break
+		}
+		if countdown := g.sharedContent.GetCountdownSeconds(); countdown >= 0 {
 			g.sharedContent.Countdown--
+			if newCountdown := g.sharedContent.GetCountdownSeconds(); newCountdown != countdown {
+				g.signalCountdown(newCountdown)
+			}
 		}
 		if g.sharedContent.FadeCountdown > 0 {
 			g.sharedContent.FadeCountdown--
 			if g.sharedContent.FadeCountdown == 0 {
-				g.sharedContent.SwitchToScoreboardStage()
+				g.sharedContent.SwitchToScoreboardStage(g.allTimeNemesis)
 				break
 			}
 		}
@@ -73,16 +142,12 @@ func (g *Game) Update() error {
 			if g.sharedContent.FadeCountdown == 0 {
 				if controller.IsUpJustPressed() {
 					direction = Up
-					log.Debug().Int("snakeId", snake.Id).Str("direction", "Up").Msg("New direction")
 				} else if controller.IsDownJustPressed() {
 					direction = Down
-					log.Debug().Int("snakeId", snake.Id).Str("direction", "Down").Msg("New direction")
 				} else if controller.IsLeftJustPressed() {
 					direction = Left
-					log.Debug().Int("snakeId", snake.Id).Str("direction", "Left").Msg("New direction")
 				} else if controller.IsRightJustPressed() {
 					direction = Right
-					log.Debug().Int("snakeId", snake.Id).Str("direction", "Right").Msg("New direction")
 				}
 			}
 			nX, nY := newHeadCoords(snake, direction)
@@ -91,8 +156,15 @@ func (g *Game) Update() error {
 				direction = snake.Direction
 				nX, nY = newHeadCoords(snake, direction)
 			}
+			if direction != snake.Direction {
+				g.sharedContent.Events.Emit(event.DirectionChanged{SnakeId: snake.Id, Direction: direction.String()})
+			}
 			if g.sharedContent.ActionFrameCount%model.TpsMultiplier == 0 {
-				if g.sharedContent.Grid[nY][nX] == nil {
+				for _, link := range snake.Links {
+					link.RememberPosition()
+				}
+				moved := g.sharedContent.Grid[nY][nX] == nil
+				if moved {
 					tail := snake.Links[len(snake.Links)-1]
 					oldTailX := tail.X
 					oldTailY := tail.Y
@@ -110,6 +182,8 @@ func (g *Game) Update() error {
 							SnakeId:       snake.Id,
 							X:             oldTailX,
 							Y:             oldTailY,
+							PrevX:         oldTailX,
+							PrevY:         oldTailY,
 							Redness:       0,
 						})
 					} else {
@@ -122,14 +196,13 @@ func (g *Game) Update() error {
 						g.sharedContent.EatApple(snake)
 					}
 				} else if g.sharedContent.FadeCountdown == 0 {
-					switch item := g.sharedContent.Grid[nY][nX].(type) {
-					case *Link:
-						idx := slices.Index(g.sharedContent.Snakes[item.SnakeId].Links, item)
-						if idx > 0 {
-							g.biteSnake(item, snake, idx)
-						}
+					item := g.sharedContent.Grid[nY][nX]
+					idx := slices.Index(g.sharedContent.Snakes[item.SnakeId].Links, item)
+					if idx > 0 {
+						g.biteSnake(item, snake, idx)
 					}
 				}
+				g.sharedContent.Stats.RecordStep(snake.Id, len(snake.Links), moved)
 			}
 			snake.Direction = direction
 		}
@@ -137,33 +210,117 @@ func (g *Game) Update() error {
 		g.sharedContent.ActionFrameCount++
 	case shared.Scoreboard:
 		g.updateScoreboard()
+	case shared.Bracket:
+		g.updateBracket()
 	}
 	return nil
 }
 
+// isExitJustPressed checks Escape separately, so that the keyboard can pause the action even when it hasn't joined.
+func (g *Game) isExitJustPressed() bool {
+	pressed := inpututil.IsKeyJustPressed(ebiten.KeyEscape)
+	for _, snake := range g.sharedContent.Snakes {
+		pressed = pressed || g.activeControllers[snake.Id].IsExitJustPressed()
+	}
+	return pressed
+}
+
+func (g *Game) updatePause() {
+	menu := g.sharedContent.Pause
+	for _, pos := range pointer.AppendJustClicked(nil) {
+		if item, found := menu.ItemAt(pos.Y); found {
+			menu.Select(item)
+			g.selectPauseMenuItem(item)
+			return
+		}
+	}
+	if g.isExitJustPressed() {
+		if menu.IsConfirmingQuit() {
+			menu.Back()
+		} else {
+			g.resumeAction()
+		}
+		return
+	}
+	for _, snake := range g.sharedContent.Snakes {
+		c := g.activeControllers[snake.Id]
+		if c.IsUpPressed() {
+			menu.MoveUp()
+		} else if c.IsDownPressed() {
+			menu.MoveDown()
+		} else if c.IsStartJustPressed() {
+			g.selectPauseMenuItem(menu.Selected())
+			return
+		}
+	}
+}
+
+func (g *Game) selectPauseMenuItem(item pausemenu.Item) {
+	menu := g.sharedContent.Pause
+	switch item {
+	case pausemenu.Resume:
+		g.resumeAction()
+	case pausemenu.RestartRound:
+		g.sharedContent.SwitchToLobbyStage()
+		g.startAction()
+	case pausemenu.BackToLobby:
+		g.sharedContent.SwitchToLobbyStage()
+	case pausemenu.Quit:
+		menu.AskToConfirmQuit()
+	case pausemenu.QuitCancelled:
+		menu.Back()
+	case pausemenu.QuitConfirmed:
+		log.Info().Msg("Exiting game")
+		os.Exit(0)
+	}
+}
+
+func (g *Game) resumeAction() {
+	g.sharedContent.ResumeAction()
+	g.signalCountdown(g.sharedContent.GetResumeCountdownSeconds())
+}
+
+func (g *Game) updateResumeCountdown() {
+	countdown := g.sharedContent.GetResumeCountdownSeconds()
+	g.sharedContent.ResumeCountdown--
+	if newCountdown := g.sharedContent.GetResumeCountdownSeconds(); newCountdown != countdown {
+		g.signalCountdown(newCountdown)
+	}
+}
+
 func (g *Game) biteSnake(bittenLink *Link, bitingSnake *Snake, idx int) {
 	targetSnake := g.sharedContent.Snakes[bittenLink.SnakeId]
 	bittenLink.HealthPercent -= model.HealthReductionPerBite
 	bittenLink.Redness = 1
-	g.activeControllers[targetSnake.Id].Vibrate(200 * time.Millisecond)
+	g.sharedContent.Events.Emit(event.LinkBitten{
+		SnakeId:       bitingSnake.Id,
+		TargetSnakeId: targetSnake.Id,
+		LinkIndex:     idx,
+		HealthPercent: int(bittenLink.HealthPercent),
+		X:             bittenLink.X,
+		Y:             bittenLink.Y,
+	})
 	if targetSnake != bitingSnake {
 		g.sharedContent.IncScore(bitingSnake, model.BitLinkScore)
 	}
 	if bittenLink.HealthPercent <= 0 {
+		nippedTailLength := len(targetSnake.Links) - idx
 		if targetSnake != bitingSnake {
-			nippedTailLength := len(targetSnake.Links) - idx
-			log.Debug().
-				Int("bitingSnakeId", bitingSnake.Id).
-				Int("targetSnakeId", targetSnake.Id).
-				Int("nippedTailLength", nippedTailLength).
-				Msg("Nip!")
 			g.sharedContent.IncScore(bitingSnake, nippedTailLength*model.NippedTailLinkBonusMultiplier)
 		}
+		nippedCells := make([][2]int, 0, nippedTailLength)
 		for i := idx; i < len(targetSnake.Links); i++ {
 			link := targetSnake.Links[i]
 			g.sharedContent.Grid[link.Y][link.X] = nil
+			nippedCells = append(nippedCells, [2]int{link.X, link.Y})
 		}
 		targetSnake.Links = targetSnake.Links[:idx]
+		g.sharedContent.Events.Emit(event.TailNipped{
+			SnakeId:       bitingSnake.Id,
+			TargetSnakeId: targetSnake.Id,
+			Length:        nippedTailLength,
+			Cells:         nippedCells,
+		})
 	}
 }
 
@@ -171,6 +328,18 @@ func (g *Game) updateHeadCount() {
 	for _, snake := range g.sharedContent.Snakes {
 		snake.Links[0].ChangeRedness(-0.1)
 	}
+	if g.sharedContent.CanStart() &&
+		g.localContent.GetStage() == local.Off &&
+		pointer.IsJustClickedIn(shared.LobbyStartBounds()) {
+		g.startAction()
+		return
+	}
+	if g.localContent.GetStage() == local.Off && pointer.IsJustClickedIn(shared.LobbyThemeBounds()) {
+		g.switchTheme(true)
+	}
+	if g.updateLeaving() {
+		return
+	}
 	g.controllers = input.Controllers()
 	for _, c := range g.controllers {
 		if c.IsAnyJustPressed() {
@@ -179,46 +348,310 @@ func (g *Game) updateHeadCount() {
 			snakeIdx := slices.IndexFunc(snakes, func(snake *Snake) bool { return g.activeControllers[snake.Id].Equals(c) })
 			if snakeIdx == -1 {
 				if snakeCount < model.MaxSnakes && g.localContent.GetStage() == local.Off {
-					// Start name entry for new player
-					g.localContent.SwitchToPlayerNameStage(
+					// Start profile choice or name entry for new player
+					g.localContent.SwitchToPlayerProfileStage(
 						c,
+						g.profileOptions(c),
 						"Player "+string(rune('0'+(snakeCount+1))),
-						common.SnakeColours[snakeCount],
-						func(s string) {
-							// Submit name and join game
+						g.pickColour(nil),
+						g.validateName(nil),
+						func(p *profile.Profile, s string) {
+							// Submit name, then choose colour and join game
 							playerName := strings.TrimSpace(s)
-							snakes := g.sharedContent.Snakes
-							snakeCount := len(snakes)
-							for _, snake := range snakes {
-								head := snake.Links[0]
-								g.sharedContent.Grid[head.Y][head.X] = nil
-							}
-							newSnake := NewSnake(snakeCount, playerName, common.SnakeColours[snakeCount])
-							g.sharedContent.Snakes = append(g.sharedContent.Snakes, newSnake)
-							g.activeControllers = append(g.activeControllers, c)
-							g.sharedContent.LayoutSnakes()
-							log.Info().Str("name", playerName).Int("id", snakeCount).Msg("Player joined")
+							g.localContent.SwitchToPlayerColourStage(
+								c,
+								playerName,
+								g.untakenColours(),
+								g.pickColour(p),
+								func(colour color.Color) error {
+									if g.isColourTaken(colour) {
+										return errors.New("another player has this colour")
+									}
+									return nil
+								},
+								func(colour color.Color) {
+									g.joinPlayer(c, playerName, colour)
+								},
+							)
 						},
 					)
 				}
 			} else {
 				snakes[snakeIdx].Links[0].Redness = 1
-				if c.IsStartJustPressed() && snakeCount > 1 {
-					g.sharedContent.Stage = shared.Action
-					log.Info().Int("tagetScore", model.TargetScore).Msg("Action started!")
+				if c.IsStartJustPressed() && g.sharedContent.CanStart() {
+					g.startAction()
+					return
+				}
+				if c.IsStartJustPressed() && g.sharedContent.CanForfeit() && g.localContent.GetStage() == local.Off {
+					g.playWithoutMissing()
+					return
+				}
+				// while someone types a name or picks a colour, the keys may be shared with their keyboard
+				if g.localContent.GetStage() != local.Off {
+					continue
+				}
+				g.updateRumbleIntensity(snakeIdx, c)
+				if c.IsLeftJustPressed() {
+					g.switchTheme(false)
+				} else if c.IsRightJustPressed() {
+					g.switchTheme(true)
 				}
 			}
 		}
 	}
 }
 
+func (g *Game) joinPlayer(c controller.Controller, playerName string, colour color.Color) {
+	snakes := g.sharedContent.Snakes
+	snakeCount := len(snakes)
+	for _, snake := range snakes {
+		head := snake.Links[0]
+		g.sharedContent.Grid[head.Y][head.X] = nil
+	}
+	newSnake := NewSnake(snakeCount, playerName, colour)
+	g.sharedContent.Snakes = append(g.sharedContent.Snakes, newSnake)
+	g.activeControllers = append(g.activeControllers, c)
+	g.exitHeldTicks = append(g.exitHeldTicks, 0)
+	g.sharedContent.LayoutSnakes()
+	newSnake.Rating = int(math.Round(g.profiles.Bind(playerName, c.Id(), colour).SkillRating()))
+	log.Info().Str("name", playerName).Int("id", snakeCount).Str("colour", profile.FormatColour(colour)).Msg("Player joined")
+}
+
+// updateLeaving watches the exit buttons of the joined players: holding one for long enough
+// makes the player leave, while a shorter press lets them rename their snake. It reports whether
+// someone has left, as the ids have changed then.
+func (g *Game) updateLeaving() bool {
+	for _, snake := range g.sharedContent.Snakes {
+		c := g.activeControllers[snake.Id]
+		held := c.ExitPressDuration()
+		prevHeld := g.exitHeldTicks[snake.Id]
+		g.exitHeldTicks[snake.Id] = held
+		// an open dialog may belong to this snake, and would bind a profile to it once it's gone
+		if g.localContent.GetStage() != local.Off {
+			continue
+		}
+		if held >= model.LeaveHoldTicks {
+			g.removePlayer(snake.Id)
+			return true
+		}
+		if held == 0 && prevHeld > 0 {
+			g.renamePlayer(snake, c)
+		}
+	}
+	return false
+}
+
+// removePlayer takes a snake out of the lobby. The ones after it move one seat down,
+// so that ids stay usable as indices of Snakes, activeControllers and the like.
+func (g *Game) removePlayer(snakeId int) {
+	snakes := g.sharedContent.Snakes
+	log.Info().Str("name", snakes[snakeId].Name).Int("id", snakeId).Msg("Player left")
+	for _, snake := range snakes {
+		head := snake.Links[0]
+		g.sharedContent.Grid[head.Y][head.X] = nil
+	}
+	g.sharedContent.Snakes = slices.Delete(snakes, snakeId, snakeId+1)
+	g.activeControllers = slices.Delete(g.activeControllers, snakeId, snakeId+1)
+	g.exitHeldTicks = slices.Delete(g.exitHeldTicks, snakeId, snakeId+1)
+	for id, snake := range g.sharedContent.Snakes {
+		snake.Id = id
+		for _, link := range snake.Links {
+			link.SnakeId = id
+		}
+	}
+	if len(g.sharedContent.Snakes) > 0 {
+		g.sharedContent.LayoutSnakes()
+	}
+}
+
+func (g *Game) renamePlayer(snake *Snake, c controller.Controller) {
+	g.localContent.SwitchToPlayerNameStage(c, snake.Name, snake.Colour, g.validateName(snake), func(s string) {
+		name := strings.TrimSpace(s)
+		log.Info().Str("oldName", snake.Name).Str("name", name).Msg("Player renamed")
+		snake.Name = name
+		snake.Rating = int(math.Round(g.profiles.Bind(name, c.Id(), snake.Colour).SkillRating()))
+	})
+}
+
+// validateName turns down the names other snakes than a given one already have, ignoring case like
+// the profiles do, so that two players never share a profile, a rating or a heat.
+func (g *Game) validateName(self *Snake) func(string) error {
+	return func(s string) error {
+		name := strings.TrimSpace(s)
+		if slices.ContainsFunc(g.sharedContent.Snakes, func(other *Snake) bool {
+			return other != self && strings.EqualFold(other.Name, name)
+		}) {
+			return errors.New("another player has this name")
+		}
+		return nil
+	}
+}
+
+// isEscapeTaken tells whether a player on the arrow keys has joined the lobby, using Escape as their exit button.
+func (g *Game) isEscapeTaken() bool {
+	if g.sharedContent.Stage != shared.Lobby {
+		return false
+	}
+	return slices.ContainsFunc(g.activeControllers, func(c controller.Controller) bool { return c.Equals(keyboard.Instance) })
+}
+
+// profileOptions lists the stored profiles a player joining with a given controller may pick from,
+// leaving out the ones which have already joined.
+func (g *Game) profileOptions(c controller.Controller) []profilepicker.Option {
+	var options []profilepicker.Option
+	for _, p := range g.profiles.Suggest(c.Id()) {
+		if slices.ContainsFunc(g.sharedContent.Snakes, func(s *Snake) bool { return strings.EqualFold(s.Name, p.Name) }) {
+			continue
+		}
+		colour, ok := p.PreferredColour()
+		if !ok {
+			colour = color.White
+		}
+		options = append(options, profilepicker.Option{Profile: p, Colour: colour})
+	}
+	return options
+}
+
+// pickColour chooses the preferred colour of a profile if no one else has taken it,
+// or the first colour not taken otherwise.
+func (g *Game) pickColour(p *profile.Profile) color.Color {
+	if p != nil {
+		if colour, ok := p.PreferredColour(); ok && !g.isColourTaken(colour) {
+			return colour
+		}
+	}
+	palette := theme.Current().SnakeColours
+	for _, colour := range palette {
+		if !g.isColourTaken(colour) {
+			return colour
+		}
+	}
+	return palette[0]
+}
+
+// untakenColours lists the colours of the current palette nobody has taken yet.
+func (g *Game) untakenColours() []color.Color {
+	var colours []color.Color
+	for _, colour := range theme.Current().SnakeColours {
+		if !g.isColourTaken(colour) {
+			colours = append(colours, colour)
+		}
+	}
+	return colours
+}
+
+// switchTheme moves to the next or previous theme. Snakes wearing a colour of the old palette
+// get the matching colour of the new one, while custom colours are kept.
+func (g *Game) switchTheme(forward bool) {
+	oldPalette := theme.Current().SnakeColours
+	if forward {
+		theme.Next()
+	} else {
+		theme.Previous()
+	}
+	newPalette := theme.Current().SnakeColours
+	for _, snake := range g.sharedContent.Snakes {
+		if idx := slices.IndexFunc(oldPalette[:], func(c color.Color) bool { return common.SameColour(c, snake.Colour) }); idx != -1 {
+			snake.Colour = newPalette[idx]
+		}
+	}
+}
+
+func (g *Game) isColourTaken(colour color.Color) bool {
+	return slices.ContainsFunc(g.sharedContent.Snakes, func(s *Snake) bool { return common.SameColour(s.Colour, colour) })
+}
+
+func (g *Game) startAction() {
+	g.sharedContent.Stage = shared.Action
+	players := make([]string, len(g.sharedContent.Snakes))
+	for i, snake := range g.sharedContent.Snakes {
+		players[i] = snake.Name
+	}
+	g.sharedContent.Events.Emit(event.MatchStarted{Players: players, TargetScore: model.TargetScore})
+	g.signalCountdown(g.sharedContent.GetCountdownSeconds())
+	log.Info().Int("tagetScore", model.TargetScore).Msg("Action started!")
+}
+
+// updateRumbleIntensity lets a joined player adjust their rumble in the lobby with up and down,
+// previewing the new intensity straight away.
+func (g *Game) updateRumbleIntensity(snakeId int, c controller.Controller) {
+	snake := g.sharedContent.Snakes[snakeId]
+	intensity := snake.Rumble
+	if c.IsUpJustPressed() {
+		intensity = intensity.Stronger()
+	} else if c.IsDownJustPressed() {
+		intensity = intensity.Weaker()
+	} else {
+		return
+	}
+	snake.Rumble = intensity
+	g.rumble(snakeId, haptics.Bite)
+	log.Info().Int("snakeId", snakeId).Stringer("intensity", intensity).Msg("Rumble intensity changed")
+}
+
+func (g *Game) rumble(snakeId int, pattern haptics.Pattern) {
+	g.haptics.Play(g.activeControllers[snakeId], g.sharedContent.Snakes[snakeId].Rumble, pattern)
+}
+
+// signalCountdown beeps and rumbles at THREE, TWO, ONE and when the countdown is over.
+func (g *Game) signalCountdown(countdown int) {
+	var pattern haptics.Pattern
+	var effect sound.Effect
+	switch {
+	case countdown > 0 && countdown <= 3:
+		pattern = haptics.Countdown
+		effect = sound.Countdown
+	case countdown == 0:
+		pattern = haptics.Go
+		effect = sound.Go
+	default:
+		return
+	}
+	g.sounds.PlayVariant(effect, countdown)
+	for _, snake := range g.sharedContent.Snakes {
+		g.rumble(snake.Id, pattern)
+	}
+}
+
+// updateMusic plays the action loop while snakes are moving and the lobby loop elsewhere, going quiet during pauses.
+func (g *Game) updateMusic() {
+	switch {
+	case g.sharedContent.Stage != shared.Action:
+		g.sounds.PlayMusic(sound.LobbyMusic)
+	case g.sharedContent.Pause != nil:
+		g.sounds.PlayMusic(sound.NoMusic)
+	default:
+		g.sounds.PlayMusic(sound.ActionMusic)
+	}
+}
+
 func (g *Game) updateScoreboard() {
+	if pointer.IsJustClickedIn(scoreboard.StartBounds()) {
+		g.playAgain()
+		return
+	}
+	if pointer.IsJustClickedIn(scoreboard.QuitBounds()) {
+		log.Info().Msg("Exiting game")
+		os.Exit(0)
+	}
+	if pointer.IsJustClickedIn(scoreboard.PreviousPageBounds()) {
+		g.sharedContent.FlipScoreboardPage(false)
+	}
+	if pointer.IsJustClickedIn(scoreboard.NextPageBounds()) {
+		g.sharedContent.FlipScoreboardPage(true)
+	}
 	for _, snake := range g.sharedContent.Snakes {
 		controller := g.activeControllers[snake.Id]
 		if controller.IsStartJustPressed() {
-			g.sharedContent.SwitchToLobbyStage()
-		} else if controller.IsExitJustPressed() {
+			g.playAgain()
+			return
+		} else if _, isTouch := controller.(touch.Touch); controller.IsExitJustPressed() && !isTouch {
+			// a long press is a touch's exit, which is too easy to do by accident, so it quits with the button only
 			os.Exit(0)
+		} else if controller.IsLeftJustPressed() {
+			g.sharedContent.FlipScoreboardPage(false)
+		} else if controller.IsRightJustPressed() {
+			g.sharedContent.FlipScoreboardPage(true)
 		}
 		for _, link := range snake.Links {
 			link.ChangeRedness(-0.1)
@@ -231,6 +664,63 @@ func (g *Game) updateScoreboard() {
 	}
 }
 
+// playAgain goes back to the lobby after the scoreboard, or to the bracket in the tournament mode.
+func (g *Game) playAgain() {
+	if g.sharedContent.Tournament == nil {
+		g.sharedContent.SwitchToLobbyStage()
+		return
+	}
+	g.sharedContent.SwitchToBracketStage()
+	// the players of the next heat may stay, everyone else makes room for them
+	if heat := g.sharedContent.CurrentHeat(); heat != nil {
+		for id := len(g.sharedContent.Snakes) - 1; id >= 0; id-- {
+			if !heat.Has(g.sharedContent.Snakes[id].Name) {
+				g.removePlayer(id)
+			}
+		}
+	}
+}
+
+// playWithoutMissing starts the tournament heat without the players who haven't shown up, who forfeit it.
+// A player left alone wins the heat without playing.
+func (g *Game) playWithoutMissing() {
+	missing, _ := g.sharedContent.HeatLineup()
+	log.Info().Strs("missing", missing).Msg("Heat forfeited")
+	if len(g.sharedContent.Snakes) > 1 {
+		g.startAction()
+		return
+	}
+	g.sharedContent.Tournament.RecordHeat(map[string]int{g.sharedContent.Snakes[0].Name: 0})
+	g.playAgain()
+}
+
+// updateBracket waits for anyone to press start, joined or not, to go on to the lobby. Once the champion
+// has been shown, the tournament is left behind, and the matches that follow are free ones.
+func (g *Game) updateBracket() {
+	pressed := pointer.IsJustClickedIn(bracket.StartBounds(g.sharedContent.Tournament))
+	g.controllers = input.Controllers()
+	for _, c := range g.controllers {
+		pressed = pressed || c.IsStartJustPressed()
+	}
+	if pressed {
+		if g.sharedContent.Tournament.IsOver() {
+			g.sharedContent.Tournament = nil
+		}
+		g.sharedContent.Stage = shared.Lobby
+	}
+}
+
+// updateCursorMode shows the mouse cursor in menus, but keeps it out of the way during the action.
+func (g *Game) updateCursorMode() {
+	mode := ebiten.CursorModeVisible
+	if g.sharedContent.Stage == shared.Action && g.sharedContent.Pause == nil {
+		mode = ebiten.CursorModeHidden
+	}
+	if ebiten.CursorMode() != mode {
+		ebiten.SetCursorMode(mode)
+	}
+}
+
 func (g *Game) isAnyButtonPressed(id ebiten.GamepadID) bool {
 	buttonPressed := false
 	for b := ebiten.StandardGamepadButton(0); b <= ebiten.StandardGamepadButtonMax; b++ {
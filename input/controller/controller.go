@@ -10,6 +10,9 @@ import (
 
 type Controller interface {
 	Equals(controller Controller) bool
+	// Id identifies the kind of device and its layout. Unlike the values
+	// of controllers themselves, it survives restarts of the game.
+	Id() string
 	IsAnyJustPressed() bool
 	IsAnyPressed() bool
 	IsUpJustPressed() bool
@@ -22,9 +25,11 @@ type Controller interface {
 	IsRightPressed() bool
 	IsExitJustPressed() bool
 	IsExitPressed() bool
+	// ExitPressDuration is the number of ticks the exit button has been held for, 0 when it's released
+	ExitPressDuration() int
 	IsStartJustPressed() bool
 	IsStartPressed() bool
-	Vibrate(duration time.Duration)
+	Vibrate(duration time.Duration, strongMagnitude, weakMagnitude float64)
 }
 
 func IsRepeatingGamepad(id ebiten.GamepadID, buttons ...ebiten.StandardGamepadButton) bool {
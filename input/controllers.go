@@ -5,6 +5,7 @@ import (
 	"snakehem/input/gamepad"
 	"snakehem/input/keyboard"
 	"snakehem/input/keyboardwasd"
+	"snakehem/input/touch"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -16,5 +17,10 @@ func Controllers() []controller.Controller {
 	for _, g := range ebiten.AppendGamepadIDs(nil) {
 		result = append(result, gamepad.NewGamepad(g))
 	}
+	if touch.IsUsed() {
+		for r := touch.Region(0); r < touch.RegionCount; r++ {
+			result = append(result, touch.NewTouch(r))
+		}
+	}
 	return result
 }
@@ -19,6 +19,10 @@ func (g Gamepad) Equals(controller controller.Controller) bool {
 	return ok && g == other
 }
 
+func (g Gamepad) Id() string {
+	return "gamepad:" + ebiten.GamepadSDLID(ebiten.GamepadID(g))
+}
+
 func (g Gamepad) IsAnyJustPressed() bool {
 	buttonPressed := false
 	for b := ebiten.StandardGamepadButton(0); b <= ebiten.StandardGamepadButtonMax; b++ {
@@ -98,6 +102,10 @@ func (g Gamepad) IsExitPressed() bool {
 	return controller.IsRepeatingGamepad(ebiten.GamepadID(g), ebiten.StandardGamepadButtonCenterLeft)
 }
 
+func (g Gamepad) ExitPressDuration() int {
+	return inpututil.StandardGamepadButtonPressDuration(ebiten.GamepadID(g), ebiten.StandardGamepadButtonCenterLeft)
+}
+
 func (g Gamepad) IsStartJustPressed() bool {
 	return inpututil.IsStandardGamepadButtonJustPressed(ebiten.GamepadID(g), ebiten.StandardGamepadButtonCenterRight)
 }
@@ -106,10 +114,10 @@ func (g Gamepad) IsStartPressed() bool {
 	return controller.IsRepeatingGamepad(ebiten.GamepadID(g), ebiten.StandardGamepadButtonCenterRight)
 }
 
-func (g Gamepad) Vibrate(duration time.Duration) {
+func (g Gamepad) Vibrate(duration time.Duration, strongMagnitude, weakMagnitude float64) {
 	ebiten.VibrateGamepad(ebiten.GamepadID(g), &ebiten.VibrateGamepadOptions{
 		Duration:        duration,
-		StrongMagnitude: 1,
-		WeakMagnitude:   1,
+		StrongMagnitude: strongMagnitude,
+		WeakMagnitude:   weakMagnitude,
 	})
 }
@@ -0,0 +1,136 @@
+package haptics
+
+import (
+	"snakehem/input/controller"
+	"snakehem/model"
+	"time"
+)
+
+// Pulse is a single rumble of a controller. Delay is counted from the start of the pattern.
+type Pulse struct {
+	Delay           time.Duration
+	Duration        time.Duration
+	StrongMagnitude float64
+	WeakMagnitude   float64
+}
+
+type Pattern []Pulse
+
+var (
+	// Bite is felt by a player who bites someone else.
+	Bite = Pattern{
+		{Duration: 50 * time.Millisecond, StrongMagnitude: 0, WeakMagnitude: 0.6},
+	}
+	// Bitten is felt by a player whose link has been bitten.
+	Bitten = Pattern{
+		{Duration: 200 * time.Millisecond, StrongMagnitude: 1, WeakMagnitude: 1},
+	}
+	// TailLost is felt by a player whose tail has been nipped off.
+	TailLost = Pattern{
+		{Duration: 600 * time.Millisecond, StrongMagnitude: 1, WeakMagnitude: 0.5},
+	}
+	// AppleEaten is felt by a player who has eaten an apple.
+	AppleEaten = Pattern{
+		{Duration: 70 * time.Millisecond, StrongMagnitude: 0.2, WeakMagnitude: 0.8},
+		{Delay: 150 * time.Millisecond, Duration: 70 * time.Millisecond, StrongMagnitude: 0.2, WeakMagnitude: 0.8},
+	}
+	// Countdown is felt by everyone at THREE, TWO and ONE.
+	Countdown = Pattern{
+		{Duration: 100 * time.Millisecond, StrongMagnitude: 0.3, WeakMagnitude: 0.3},
+	}
+	// Go is felt by everyone when the countdown is over.
+	Go = Pattern{
+		{Duration: 250 * time.Millisecond, StrongMagnitude: 0.8, WeakMagnitude: 0.8},
+	}
+)
+
+type Intensity uint8
+
+const (
+	Off Intensity = iota
+	Low
+	Medium
+	High
+)
+
+func (i Intensity) Magnitude() float64 {
+	return float64(i) / float64(High)
+}
+
+func (i Intensity) String() string {
+	switch i {
+	case Off:
+		return "OFF"
+	case Low:
+		return "LOW"
+	case Medium:
+		return "MEDIUM"
+	default:
+		return "HIGH"
+	}
+}
+
+func (i Intensity) Stronger() Intensity {
+	if i < High {
+		return i + 1
+	}
+	return i
+}
+
+func (i Intensity) Weaker() Intensity {
+	if i > Off {
+		return i - 1
+	}
+	return i
+}
+
+type scheduledPulse struct {
+	controller controller.Controller
+	pulse      Pulse
+	magnitude  float64
+	tick       int64
+}
+
+// Haptics plays rumble patterns, which may span several ticks, on top of Controller.Vibrate.
+type Haptics struct {
+	enabled bool
+	tick    int64
+	pending []scheduledPulse
+}
+
+func NewHaptics(enabled bool) *Haptics {
+	return &Haptics{
+		enabled: enabled,
+		tick:    0,
+		pending: nil,
+	}
+}
+
+// Play schedules a pattern on a controller, scaling its magnitudes by the player's intensity setting.
+func (h *Haptics) Play(c controller.Controller, intensity Intensity, pattern Pattern) {
+	if !h.enabled || intensity == Off {
+		return
+	}
+	for _, p := range pattern {
+		h.pending = append(h.pending, scheduledPulse{
+			controller: c,
+			pulse:      p,
+			magnitude:  intensity.Magnitude(),
+			tick:       h.tick + int64(p.Delay*model.Tps/time.Second),
+		})
+	}
+}
+
+// Update fires the pulses that are due. It is expected to be called once per tick.
+func (h *Haptics) Update() {
+	remaining := h.pending[:0]
+	for _, s := range h.pending {
+		if s.tick > h.tick {
+			remaining = append(remaining, s)
+			continue
+		}
+		s.controller.Vibrate(s.pulse.Duration, s.pulse.StrongMagnitude*s.magnitude, s.pulse.WeakMagnitude*s.magnitude)
+	}
+	h.pending = remaining
+	h.tick++
+}
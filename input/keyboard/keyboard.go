@@ -18,6 +18,10 @@ func (k keyboard) Equals(controller controller.Controller) bool {
 	return ok
 }
 
+func (k keyboard) Id() string {
+	return "keyboard:arrows"
+}
+
 func (k keyboard) IsAnyJustPressed() bool {
 	return k.IsUpJustPressed() || k.IsDownJustPressed() || k.IsLeftJustPressed() ||
 		k.IsRightJustPressed() || k.IsExitJustPressed() || k.IsStartJustPressed()
@@ -68,6 +72,10 @@ func (k keyboard) IsExitPressed() bool {
 	return controller.IsRepeatingKeyboard(ebiten.KeyEscape)
 }
 
+func (k keyboard) ExitPressDuration() int {
+	return inpututil.KeyPressDuration(ebiten.KeyEscape)
+}
+
 func (k keyboard) IsStartJustPressed() bool {
 	return inpututil.IsKeyJustPressed(ebiten.KeyAltRight)
 }
@@ -76,6 +84,6 @@ func (k keyboard) IsStartPressed() bool {
 	return controller.IsRepeatingKeyboard(ebiten.KeyAltRight)
 }
 
-func (k keyboard) Vibrate(_ time.Duration) {
+func (k keyboard) Vibrate(_ time.Duration, _, _ float64) {
 	// nothing
 }
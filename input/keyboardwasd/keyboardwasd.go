@@ -18,6 +18,10 @@ func (k keyboardWasd) Equals(controller controller.Controller) bool {
 	return ok
 }
 
+func (k keyboardWasd) Id() string {
+	return "keyboard:wasd"
+}
+
 func (k keyboardWasd) IsAnyJustPressed() bool {
 	return k.IsUpJustPressed() || k.IsDownJustPressed() || k.IsLeftJustPressed() ||
 		k.IsRightJustPressed() || k.IsExitJustPressed() || k.IsStartJustPressed()
@@ -68,6 +72,10 @@ func (k keyboardWasd) IsExitPressed() bool {
 	return controller.IsRepeatingKeyboard(ebiten.KeyControlLeft)
 }
 
+func (k keyboardWasd) ExitPressDuration() int {
+	return inpututil.KeyPressDuration(ebiten.KeyControlLeft)
+}
+
 func (k keyboardWasd) IsStartJustPressed() bool {
 	return inpututil.IsKeyJustPressed(ebiten.KeyAltLeft)
 }
@@ -76,6 +84,6 @@ func (k keyboardWasd) IsStartPressed() bool {
 	return controller.IsRepeatingKeyboard(ebiten.KeyAltLeft)
 }
 
-func (k keyboardWasd) Vibrate(_ time.Duration) {
+func (k keyboardWasd) Vibrate(_ time.Duration, _, _ float64) {
 	// nothing
 }
@@ -0,0 +1,43 @@
+package pointer
+
+import (
+	"image"
+	"snakehem/display"
+	"snakehem/input/touch"
+	"snakehem/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// AppendJustClicked appends the positions of left mouse button clicks and touchscreen taps.
+func AppendJustClicked(positions []util.Coords) []util.Coords {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := display.ToFrame(ebiten.CursorPosition())
+		positions = append(positions, util.Coords{X: x, Y: y})
+	}
+	return touch.AppendJustTapped(positions)
+}
+
+func IsJustClickedIn(rect image.Rectangle) bool {
+	for _, pos := range AppendJustClicked(nil) {
+		if image.Pt(pos.X, pos.Y).In(rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hover returns the position of the mouse cursor, unless the cursor is hidden.
+func Hover() (int, int, bool) {
+	if ebiten.CursorMode() != ebiten.CursorModeVisible {
+		return 0, 0, false
+	}
+	x, y := display.ToFrame(ebiten.CursorPosition())
+	return x, y, true
+}
+
+func IsHovering(rect image.Rectangle) bool {
+	x, y, ok := Hover()
+	return ok && image.Pt(x, y).In(rect)
+}
@@ -0,0 +1,102 @@
+package touch
+
+import (
+	"snakehem/input/controller"
+	"strconv"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type Touch Region
+
+func NewTouch(region Region) Touch {
+	return Touch(region)
+}
+
+func (t Touch) Region() Region {
+	return Region(t)
+}
+
+func (t Touch) Equals(controller controller.Controller) bool {
+	other, ok := controller.(Touch)
+	return ok && t == other
+}
+
+func (t Touch) Id() string {
+	return "touch:" + strconv.Itoa(int(t))
+}
+
+func (t Touch) IsAnyJustPressed() bool {
+	return t.IsUpJustPressed() || t.IsDownJustPressed() || t.IsLeftJustPressed() ||
+		t.IsRightJustPressed() || t.IsExitJustPressed() || t.IsStartJustPressed()
+}
+
+func (t Touch) IsAnyPressed() bool {
+	return t.IsAnyJustPressed() || t.IsUpPressed() || t.IsDownPressed() || t.IsLeftPressed() ||
+		t.IsRightPressed() || t.IsStartPressed() || t.IsExitPressed()
+}
+
+func (t Touch) IsUpJustPressed() bool {
+	return events(t.Region()).justPressed[up]
+}
+
+func (t Touch) IsUpPressed() bool {
+	return events(t.Region()).repeating[up]
+}
+
+func (t Touch) IsDownJustPressed() bool {
+	return events(t.Region()).justPressed[down]
+}
+
+func (t Touch) IsDownPressed() bool {
+	return events(t.Region()).repeating[down]
+}
+
+func (t Touch) IsLeftJustPressed() bool {
+	return events(t.Region()).justPressed[left]
+}
+
+func (t Touch) IsLeftPressed() bool {
+	return events(t.Region()).repeating[left]
+}
+
+func (t Touch) IsRightJustPressed() bool {
+	return events(t.Region()).justPressed[right]
+}
+
+func (t Touch) IsRightPressed() bool {
+	return events(t.Region()).repeating[right]
+}
+
+// IsExitJustPressed reports a long press which hasn't turned into a swipe.
+func (t Touch) IsExitJustPressed() bool {
+	return events(t.Region()).longPress
+}
+
+func (t Touch) IsExitPressed() bool {
+	return t.IsExitJustPressed()
+}
+
+// ExitPressDuration counts from the touch start, but only once it has become a long press.
+func (t Touch) ExitPressDuration() int {
+	return longPressDuration(t.Region())
+}
+
+// IsStartJustPressed reports a tap, which is recognised when the finger is lifted,
+// so that the tap which makes a player join doesn't leak into the next stage.
+func (t Touch) IsStartJustPressed() bool {
+	return events(t.Region()).tap
+}
+
+func (t Touch) IsStartPressed() bool {
+	return t.IsStartJustPressed()
+}
+
+func (t Touch) Vibrate(duration time.Duration, strongMagnitude, weakMagnitude float64) {
+	// a no-op on desktops, but works on phones and tablets
+	ebiten.Vibrate(&ebiten.VibrateOptions{
+		Duration:  duration,
+		Magnitude: max(strongMagnitude, weakMagnitude),
+	})
+}
@@ -0,0 +1,281 @@
+package touch
+
+import (
+	"math"
+	"snakehem/display"
+	"snakehem/model"
+	"snakehem/util"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type Region uint8
+
+const (
+	TopLeft Region = iota
+	TopRight
+	BottomLeft
+	BottomRight
+	RegionCount = iota
+)
+
+const (
+	DPadRadiusPx     = 44
+	DPadMarginPx     = 12
+	swipeThresholdPx = model.CellDimPx * 3
+	tapMaxDriftPx    = model.CellDimPx
+	longPressTicks   = model.Tps
+)
+
+type direction uint8
+
+const (
+	up direction = iota
+	down
+	left
+	right
+	directionCount = iota
+	noDirection    = directionCount
+)
+
+type regionEvents struct {
+	justPressed [directionCount]bool
+	repeating   [directionCount]bool
+	tap         bool
+	longPress   bool
+}
+
+type stroke struct {
+	region        Region
+	startX        int
+	startY        int
+	originX       int
+	originY       int
+	startTick     int64
+	onDPad        bool
+	dPadDirection direction
+	dPadSince     int64
+	swiped        bool
+	longPressed   bool
+}
+
+type tracker struct {
+	tick    int64
+	strokes map[ebiten.TouchID]*stroke
+	events  [RegionCount]regionEvents
+	used    bool
+}
+
+// state is shared by all Touch controllers and is recalculated at most once per tick,
+// so that the controllers can be created anew on each Update like gamepads are.
+var state = &tracker{
+	tick:    -1,
+	strokes: make(map[ebiten.TouchID]*stroke),
+}
+
+// Update makes sure touches are tracked on every tick, even when no Touch controller is queried.
+func Update() {
+	state.refresh()
+}
+
+// IsUsed reports whether the screen has ever been touched. Touch controllers and
+// on-screen d-pads are offered only after that, to keep them away from desktop players.
+func IsUsed() bool {
+	state.refresh()
+	return state.used
+}
+
+// DPadCentre returns the centre of the virtual d-pad of a region,
+// which is placed in the screen corner belonging to the region.
+func DPadCentre(region Region) (int, int) {
+	offset := DPadRadiusPx + DPadMarginPx
+	x := offset
+	y := offset
+	if region == TopRight || region == BottomRight {
+		x = model.GridDimPx - offset
+	}
+	if region == BottomLeft || region == BottomRight {
+		y = model.GridDimPx - offset
+	}
+	return x, y
+}
+
+// HeldDPadDirection returns the d-pad arrow which is currently held in a region
+// as Dx and Dy, both zero if none.
+func HeldDPadDirection(region Region) (int, int) {
+	state.refresh()
+	for _, s := range state.strokes {
+		if s.region == region && s.onDPad {
+			switch s.dPadDirection {
+			case up:
+				return 0, -1
+			case down:
+				return 0, 1
+			case left:
+				return -1, 0
+			case right:
+				return 1, 0
+			}
+		}
+	}
+	return 0, 0
+}
+
+// AppendJustTapped appends the positions of touches which have just ended as taps
+// anywhere on the screen.
+func AppendJustTapped(positions []util.Coords) []util.Coords {
+	state.refresh()
+	for _, id := range inpututil.AppendJustReleasedTouchIDs(nil) {
+		x, y := display.ToFrame(inpututil.TouchPositionInPreviousTick(id))
+		if s, ok := state.strokes[id]; ok && isTap(s, x, y) {
+			positions = append(positions, util.Coords{X: x, Y: y})
+		}
+	}
+	return positions
+}
+
+func events(region Region) *regionEvents {
+	state.refresh()
+	return &state.events[region]
+}
+
+// longPressDuration is the number of ticks since the start of a long press held in a region, 0 if there's none.
+func longPressDuration(region Region) int {
+	state.refresh()
+	for id, s := range state.strokes {
+		if s.region == region && s.longPressed && inpututil.TouchPressDuration(id) > 0 {
+			return int(state.tick - s.startTick)
+		}
+	}
+	return 0
+}
+
+func (t *tracker) refresh() {
+	tick := ebiten.Tick()
+	if t.tick == tick {
+		return
+	}
+	t.tick = tick
+	t.events = [RegionCount]regionEvents{}
+	// strokes of touches released during the previous tick are no longer needed
+	for id := range t.strokes {
+		if inpututil.TouchPressDuration(id) == 0 && !inpututil.IsTouchJustReleased(id) {
+			delete(t.strokes, id)
+		}
+	}
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		t.used = true
+		x, y := display.ToFrame(ebiten.TouchPosition(id))
+		region := regionAt(x, y)
+		s := &stroke{
+			region:        region,
+			startX:        x,
+			startY:        y,
+			originX:       x,
+			originY:       y,
+			startTick:     tick,
+			dPadDirection: noDirection,
+		}
+		if dir := dPadDirectionAt(region, x, y); dir != noDirection {
+			s.onDPad = true
+		}
+		t.strokes[id] = s
+	}
+	for _, id := range ebiten.AppendTouchIDs(nil) {
+		if s, ok := t.strokes[id]; ok {
+			x, y := display.ToFrame(ebiten.TouchPosition(id))
+			t.trackPressed(s, x, y)
+		}
+	}
+	for _, id := range inpututil.AppendJustReleasedTouchIDs(nil) {
+		if s, ok := t.strokes[id]; ok {
+			x, y := display.ToFrame(inpututil.TouchPositionInPreviousTick(id))
+			if isTap(s, x, y) {
+				t.events[s.region].tap = true
+			}
+		}
+	}
+}
+
+func (t *tracker) trackPressed(s *stroke, x, y int) {
+	ev := &t.events[s.region]
+	if s.onDPad {
+		dir := dPadDirectionAt(s.region, x, y)
+		if dir != s.dPadDirection {
+			s.dPadDirection = dir
+			s.dPadSince = t.tick
+			if dir != noDirection {
+				ev.justPressed[dir] = true
+				ev.repeating[dir] = true
+			}
+		} else if dir != noDirection {
+			dur := t.tick - s.dPadSince
+			if dur > model.ControllerCoolOffPeriod && dur%model.ControllerRepeatPeriod == 0 {
+				ev.repeating[dir] = true
+			}
+		}
+		return
+	}
+	dx := x - s.originX
+	dy := y - s.originY
+	if util.AbsInt(dx) >= swipeThresholdPx || util.AbsInt(dy) >= swipeThresholdPx {
+		// the origin moves along with the finger, so that one continuous stroke
+		// can make several turns in a row
+		dir := swipeDirection(dx, dy)
+		ev.justPressed[dir] = true
+		ev.repeating[dir] = true
+		s.swiped = true
+		s.originX = x
+		s.originY = y
+	} else if !s.swiped && !s.longPressed && t.tick-s.startTick >= longPressTicks && !hasDrifted(s, x, y) {
+		s.longPressed = true
+		ev.longPress = true
+	}
+}
+
+func isTap(s *stroke, x, y int) bool {
+	return !s.onDPad && !s.swiped && !s.longPressed && !hasDrifted(s, x, y)
+}
+
+func hasDrifted(s *stroke, x, y int) bool {
+	return util.AbsInt(x-s.startX) > tapMaxDriftPx || util.AbsInt(y-s.startY) > tapMaxDriftPx
+}
+
+func regionAt(x, y int) Region {
+	region := TopLeft
+	if x >= model.GridDimPx/2 {
+		region = TopRight
+	}
+	if y >= model.GridDimPx/2 {
+		region += BottomLeft
+	}
+	return region
+}
+
+func dPadDirectionAt(region Region, x, y int) direction {
+	cx, cy := DPadCentre(region)
+	dx := x - cx
+	dy := y - cy
+	if math.Hypot(float64(dx), float64(dy)) > DPadRadiusPx {
+		return noDirection
+	}
+	// a small dead zone in the middle of the d-pad
+	if util.AbsInt(dx) < DPadRadiusPx/4 && util.AbsInt(dy) < DPadRadiusPx/4 {
+		return noDirection
+	}
+	return swipeDirection(dx, dy)
+}
+
+func swipeDirection(dx, dy int) direction {
+	if util.AbsInt(dx) > util.AbsInt(dy) {
+		if dx < 0 {
+			return left
+		}
+		return right
+	}
+	if dy < 0 {
+		return up
+	}
+	return down
+}
@@ -3,8 +3,16 @@ package main
 import (
 	_ "embed"
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"snakehem/assets/shader"
+	"snakehem/assets/theme"
 	"snakehem/game"
+	"snakehem/model"
+	"snakehem/profile"
+	"snakehem/tournament"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -12,6 +20,35 @@ import (
 
 func main() {
 	debug := flag.Bool("debug", false, "enable debug logging")
+	rumble := flag.Bool("rumble", true, "enable gamepad rumble")
+	profiles := flag.String("profiles", profile.DefaultPath(), "player profiles file")
+	fullscreen := flag.Bool("fullscreen", true, "start in fullscreen mode, F11 toggles it at runtime")
+	windowed := flag.Bool("windowed", false, "start in a window, same as -fullscreen=false")
+	windowSize := flag.String("window-size", "", "window size as WIDTHxHEIGHT, fits the monitor if not set")
+	shaderSpec := flag.String("shader", "crt", "shader preset ("+strings.Join(shader.PresetNames, ", ")+
+		") or comma-separated effects, F7 cycles presets at runtime")
+	shaderParams := flag.String("shader-params", "", "shader parameters, e.g. curvature=0.5,vignette=0,scanlines=0.3")
+	shaderDir := flag.String("shader-dir", "", "directory with extra .kage effects, reloaded when changed")
+	smooth := flag.Bool("smooth", false, "draw every tick, sliding snakes between cells")
+	themeName := flag.String("theme", "classic", "visual theme, Left/Right in the lobby cycles themes")
+	themeDir := flag.String("theme-dir", theme.DefaultDir(), "directory with extra .json themes")
+	colourBlind := flag.String("colour-blind", "none",
+		"colour-blind mode (none, protanopia, deuteranopia, tritanopia) with safe palettes, patterns and head numbers")
+	reduceMotion := flag.Bool("reduce-motion", false, "disable screen shake and flashes")
+	mute := flag.Bool("mute", false, "start with the sound off, F4 toggles it at runtime")
+	effectsVolume := flag.Float64("effects-volume", 0.75, "sound effects volume from 0 to 1, F5 cycles it at runtime")
+	musicVolume := flag.Float64("music-volume", 0.5, "music volume from 0 to 1, F6 cycles it at runtime")
+	eventsOut := flag.String("events-out", "", "file to write the events of every match to as JSON lines, for analysis")
+	tournamentRoster := flag.String("tournament", "", "file with the players of a tournament, one per line, to play it heat by heat")
+	tournamentState := flag.String("tournament-state", tournament.DefaultPath(),
+		"file the tournament progress is saved to, a tournament not over yet is resumed from it")
+	heatSize := flag.Int("heat-size", model.MaxSnakes, "most players in a tournament heat, 2 for a bracket of duels")
+	advance := flag.Int("advance", 1, "number of players going on from each tournament heat")
+	metricsAddr := flag.String("metrics-addr", "", "address to serve performance and gameplay metrics on at /metrics, e.g. :9100")
+	perfDir := flag.String("perf-dir", filepath.Join(os.TempDir(), "snakehem"),
+		"directory F3 dumps the performance histograms and a CPU profile to while F2 shows the performance, "+
+			"and F1 captures a 10-second CPU profile and execution trace to")
+	pprofAddr := flag.String("pprof-addr", "", "address to serve net/http/pprof on at /debug/pprof/, e.g. localhost:6060")
 	flag.Parse()
 
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
@@ -23,6 +60,43 @@ func main() {
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	}
 
+	var windowWidth, windowHeight int
+	if *windowSize != "" {
+		if _, err := fmt.Sscanf(*windowSize, "%dx%d", &windowWidth, &windowHeight); err != nil {
+			log.Fatal().Err(err).Str("windowSize", *windowSize).Msg("Invalid window size")
+		}
+	}
+
+	params, err := shader.ParseParams(*shaderParams)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid shader parameters")
+	}
+
 	log.Info().Msg("Starting game")
-	game.Run()
+	game.Run(game.Config{
+		Rumble:           *rumble,
+		ProfilesPath:     *profiles,
+		Fullscreen:       *fullscreen && !*windowed,
+		WindowWidth:      windowWidth,
+		WindowHeight:     windowHeight,
+		Shader:           *shaderSpec,
+		ShaderParams:     params,
+		ShaderDir:        *shaderDir,
+		Smooth:           *smooth,
+		ReduceMotion:     *reduceMotion,
+		Theme:            *themeName,
+		ThemeDir:         *themeDir,
+		ColourBlind:      *colourBlind,
+		Mute:             *mute,
+		EffectsVolume:    *effectsVolume,
+		MusicVolume:      *musicVolume,
+		EventsOut:        *eventsOut,
+		TournamentRoster: *tournamentRoster,
+		TournamentPath:   *tournamentState,
+		HeatSize:         *heatSize,
+		Advance:          *advance,
+		MetricsAddr:      *metricsAddr,
+		PerfDir:          *perfDir,
+		PprofAddr:        *pprofAddr,
+	})
 }
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Counter is a gameplay figure which only goes up while the game runs.
+type Counter uint8
+
+const (
+	MatchesPlayed Counter = iota
+	MatchesAborted
+	Bites
+	TailsNipped
+	ApplesEaten
+	counterCount
+)
+
+var counters = [counterCount]struct {
+	name string
+	help string
+}{
+	{"snakehem_matches", "Matches played to the target score"},
+	{"snakehem_matches_aborted", "Matches left from the pause menu"},
+	{"snakehem_bites", "Bites on other snakes"},
+	{"snakehem_tails_nipped", "Tails nipped off other snakes"},
+	{"snakehem_apples_eaten", "Apples eaten"},
+}
+
+// Bounds are the upper bounds of the buckets the update and draw times are counted in. A tick lasts
+// about 16.7ms at 60 TPS, so the buckets are finest below that.
+var Bounds = []time.Duration{
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	16 * time.Millisecond,
+	33 * time.Millisecond,
+	66 * time.Millisecond,
+	100 * time.Millisecond,
+}
+
+// Histogram is a copy of the durations recorded so far. Counts are cumulative, indexed the same way as
+// Bounds, and don't include the durations above the last bound, which only the total Count does.
+type Histogram struct {
+	Counts []int64
+	Count  int64
+	Sum    time.Duration
+}
+
+// Perf is what the performance tracker has measured, see perftracker.PerfTracker.
+type Perf struct {
+	UpdateTime Histogram
+	DrawTime   Histogram
+	TPS        float64
+	FPS        float64
+	// UpdateGrowing and DrawGrowing are the warnings raised when the times grow too fast
+	UpdateGrowing bool
+	DrawGrowing   bool
+}
+
+// Exporter serves the latest figures of the game over HTTP in the OpenMetrics text format. The game
+// sets them on its own goroutine while the server reads them on others, hence the lock.
+type Exporter struct {
+	mu       sync.Mutex
+	counters [counterCount]uint64
+	players  int
+	perf     *Perf
+}
+
+// Serve starts serving the metrics at /metrics on a given address, in the background.
+func Serve(addr string) (*Exporter, error) {
+	e := &Exporter{
+		mu:       sync.Mutex{},
+		counters: [counterCount]uint64{},
+		players:  0,
+		perf:     nil,
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Error().Err(err).Str("addr", addr).Msg("Metrics server stopped")
+		}
+	}()
+	log.Info().Str("addr", listener.Addr().String()).Msg("Serving metrics")
+	return e, nil
+}
+
+func (e *Exporter) Inc(c Counter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.counters[c]++
+}
+
+func (e *Exporter) SetPlayers(count int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.players = count
+}
+
+// SetPerf replaces the performance figures. They're left out until they're first set, as the tracker
+// needs a moment to gather them.
+func (e *Exporter) SetPerf(perf Perf) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.perf = &perf
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.write(w)
+}
+
+func (e *Exporter) write(w io.Writer) {
+	for c, counter := range counters {
+		writeHeader(w, counter.name, "counter", counter.help)
+		fmt.Fprintf(w, "%s_total %d\n", counter.name, e.counters[c])
+	}
+	writeGauge(w, "snakehem_players", "Players who have joined", float64(e.players))
+	if p := e.perf; p != nil {
+		writeGauge(w, "snakehem_tps", "Ticks per second, averaged over the last seconds", p.TPS)
+		writeGauge(w, "snakehem_fps", "Frames per second, averaged over the last seconds", p.FPS)
+		writeGauge(w, "snakehem_update_time_growing", "1 when the update time percentiles grow too fast", boolValue(p.UpdateGrowing))
+		writeGauge(w, "snakehem_draw_time_growing", "1 when the draw time percentiles grow too fast", boolValue(p.DrawGrowing))
+		writeHistogram(w, "snakehem_update_seconds", "Time taken by the updates", p.UpdateTime)
+		writeHistogram(w, "snakehem_draw_seconds", "Time taken by the draws", p.DrawTime)
+	}
+	fmt.Fprintln(w, "# EOF")
+}
+
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	fmt.Fprintf(w, "# HELP %s %s.\n", name, help)
+}
+
+func writeGauge(w io.Writer, name, help string, value float64) {
+	writeHeader(w, name, "gauge", help)
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+}
+
+func writeHistogram(w io.Writer, name, help string, h Histogram) {
+	writeHeader(w, name, "histogram", help)
+	for i, bound := range Bounds {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bound.Seconds()), h.Counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.Sum.Seconds()))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.Count)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
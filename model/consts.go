@@ -1,16 +1,21 @@
 package model
 
 const (
-	GameSpeedFps               = 10
-	TpsMultiplier              = 6
-	Tps                        = GameSpeedFps * TpsMultiplier
-	GridSize                   = 63
+	GameSpeedFps  = 10
+	TpsMultiplier = 6
+	Tps           = GameSpeedFps * TpsMultiplier
+	GridSize      = 63
+	// CellDimPx is the side of a grid cell, and GridDimPx the side of the whole frame, in pixels
+	CellDimPx                  = 11
+	GridDimPx                  = GridSize * CellDimPx
 	ControllerRepeatIntervalHz = 5
 	ControllerRepeatPeriod     = Tps / ControllerRepeatIntervalHz
 	ControllerCoolOffPeriod    = ControllerRepeatPeriod * 2
 
 	MaxNameLength                 = 9
 	CountdownSeconds              = 4
+	ResumeCountdownSeconds        = 3
+	LeaveHoldTicks                = Tps * 2
 	SnakeTargetLength             = 50
 	HealthReductionPerBite        = 10
 	NippedTailLinkBonusMultiplier = 2
@@ -0,0 +1,242 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"snakehem/rating"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Profile is what the game remembers of a player. Every kind of controller has a fixed layout, so there
+// are no button bindings to keep: a profile is only bound to the controllers the player has joined with,
+// which puts it first in the picker when one of them joins again.
+type Profile struct {
+	Name string `json:"name"`
+	// Colour is the preferred snake colour in #rrggbb form, empty if there's no preference
+	Colour string `json:"colour,omitempty"`
+	// ControllerIds are the controllers the player has joined with, see controller.Controller.Id
+	ControllerIds []string `json:"controllerIds"`
+	Stats         Stats    `json:"stats"`
+	// Rating is the skill rating, see the rating package. It only counts once RatedMatches is above zero.
+	Rating       float64 `json:"rating,omitempty"`
+	RatedMatches int     `json:"ratedMatches,omitempty"`
+	// Rivals are the players this one has met in matches, by name
+	Rivals     map[string]*Rivalry `json:"rivals,omitempty"`
+	LastPlayed time.Time           `json:"lastPlayed"`
+}
+
+// Rivalry adds up what two players have done to each other over all their matches,
+// seen from the side of the profile it belongs to.
+type Rivalry struct {
+	Bites  int `json:"bites"`
+	Bitten int `json:"bitten"`
+	Nips   int `json:"nips"`
+	Nipped int `json:"nipped"`
+}
+
+type Stats struct {
+	MatchesPlayed int `json:"matchesPlayed"`
+	Wins          int `json:"wins"`
+	TotalScore    int `json:"totalScore"`
+	BestScore     int `json:"bestScore"`
+}
+
+// Store keeps player profiles in a JSON file. Failing to read or write the file is not fatal:
+// the game just goes on without remembering anyone.
+type Store struct {
+	path     string
+	profiles []*Profile
+}
+
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "snakehem", "profiles.json")
+}
+
+func Load(path string) *Store {
+	s := &Store{
+		path:     path,
+		profiles: nil,
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &s.profiles)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("path", path).Msg("Failed to load player profiles")
+	} else {
+		log.Info().Str("path", path).Int("count", len(s.profiles)).Msg("Loaded player profiles")
+	}
+	return s
+}
+
+func (s *Store) Save() {
+	data, err := json.MarshalIndent(s.profiles, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(s.path), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(s.path, data, 0o644)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("path", s.path).Msg("Failed to save player profiles")
+	}
+}
+
+func (s *Store) Get(name string) *Profile {
+	idx := slices.IndexFunc(s.profiles, func(p *Profile) bool { return strings.EqualFold(p.Name, name) })
+	if idx == -1 {
+		return nil
+	}
+	return s.profiles[idx]
+}
+
+// Suggest lists the profiles worth offering to a player who joins with a given controller:
+// the ones previously used with it come first, then all others, most recently played first.
+func (s *Store) Suggest(controllerId string) []*Profile {
+	result := slices.Clone(s.profiles)
+	slices.SortStableFunc(result, func(a, b *Profile) int {
+		aBound := slices.Contains(a.ControllerIds, controllerId)
+		bBound := slices.Contains(b.ControllerIds, controllerId)
+		if aBound != bBound {
+			if aBound {
+				return -1
+			}
+			return 1
+		}
+		return b.LastPlayed.Compare(a.LastPlayed)
+	})
+	return result
+}
+
+// Bind records that a player has joined with a given controller and colour,
+// creating the profile if it doesn't exist yet.
+func (s *Store) Bind(name, controllerId string, colour color.Color) *Profile {
+	p := s.Get(name)
+	if p == nil {
+		p = &Profile{
+			Name:          name,
+			ControllerIds: nil,
+		}
+		s.profiles = append(s.profiles, p)
+	}
+	if !slices.Contains(p.ControllerIds, controllerId) {
+		p.ControllerIds = append(p.ControllerIds, controllerId)
+	}
+	p.Colour = FormatColour(colour)
+	p.LastPlayed = time.Now()
+	s.Save()
+	return p
+}
+
+func (s *Store) RecordMatch(name string, score int, won bool) {
+	p := s.Get(name)
+	if p == nil {
+		return
+	}
+	p.Stats.MatchesPlayed++
+	p.Stats.TotalScore += score
+	p.Stats.BestScore = max(p.Stats.BestScore, score)
+	if won {
+		p.Stats.Wins++
+	}
+	p.LastPlayed = time.Now()
+}
+
+// RecordRivalry adds what a player has dealt to an opponent during a match. It's called for both
+// sides of every pair, so each one only needs to count what it has dealt.
+func (s *Store) RecordRivalry(name, opponent string, bites, nips int) {
+	p := s.Get(name)
+	o := s.Get(opponent)
+	if p == nil || o == nil {
+		return
+	}
+	p.rivalry(o.Name).Bites += bites
+	p.rivalry(o.Name).Nips += nips
+	o.rivalry(p.Name).Bitten += bites
+	o.rivalry(p.Name).Nipped += nips
+}
+
+func (p *Profile) rivalry(opponent string) *Rivalry {
+	if p.Rivals == nil {
+		p.Rivals = make(map[string]*Rivalry)
+	}
+	r, ok := p.Rivals[opponent]
+	if !ok {
+		r = &Rivalry{}
+		p.Rivals[opponent] = r
+	}
+	return r
+}
+
+// Nemesis is the opponent who has hurt the player the most over all matches: nips count first, then bites.
+func (p *Profile) Nemesis() (string, bool) {
+	var nemesis string
+	var worst *Rivalry
+	for name, r := range p.Rivals {
+		if r.Bitten == 0 {
+			continue
+		}
+		if worst == nil || r.Nipped > worst.Nipped || r.Nipped == worst.Nipped && r.Bitten > worst.Bitten ||
+			r.Nipped == worst.Nipped && r.Bitten == worst.Bitten && name < nemesis {
+			nemesis = name
+			worst = r
+		}
+	}
+	return nemesis, worst != nil
+}
+
+// SkillRating is the rating of the player, or the initial one for a newcomer.
+func (p *Profile) SkillRating() float64 {
+	if p.RatedMatches == 0 {
+		return rating.Initial
+	}
+	return p.Rating
+}
+
+// RecordRatings rates a finished match. Players without a profile play at the initial rating,
+// but aren't rated themselves.
+func (s *Store) RecordRatings(names []string, scores []int) {
+	ratings := make([]float64, len(names))
+	for i, name := range names {
+		ratings[i] = rating.Initial
+		if p := s.Get(name); p != nil {
+			ratings[i] = p.SkillRating()
+		}
+	}
+	for i, r := range rating.Update(ratings, scores) {
+		if p := s.Get(names[i]); p != nil {
+			p.Rating = r
+			p.RatedMatches++
+		}
+	}
+}
+
+func (p *Profile) PreferredColour() (color.Color, bool) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(p.Colour, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, false
+	}
+	return color.NRGBA{R: r, G: g, B: b, A: 255}, true
+}
+
+func FormatColour(colour color.Color) string {
+	c := color.NRGBAModel.Convert(colour).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,64 @@
+package rating
+
+import (
+	"math"
+	"slices"
+)
+
+// Initial is the rating of a player who hasn't finished a match yet.
+const Initial = 1500
+
+// k is how far a single match can move a rating. It's shared out among the opponents,
+// so that a nine-player match counts about as much as a duel.
+const k = 32
+
+// Update rates a finished match as a set of duels between every pair of players, in which the one
+// with the higher final score wins and equal scores draw. This is the order the scoreboard lists
+// the players in, ties included.
+func Update(ratings []float64, scores []int) []float64 {
+	result := slices.Clone(ratings)
+	if len(ratings) < 2 {
+		return result
+	}
+	pairK := float64(k) / float64(len(ratings)-1)
+	for i := range ratings {
+		for j := range ratings {
+			if i == j {
+				continue
+			}
+			var actual float64
+			switch {
+			case scores[i] > scores[j]:
+				actual = 1
+			case scores[i] == scores[j]:
+				actual = 0.5
+			}
+			result[i] += pairK * (actual - expected(ratings[i], ratings[j]))
+		}
+	}
+	return result
+}
+
+// expected is the chance of a player rated a beating a player rated b, counting a draw as half a win.
+func expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Rank orders the players from the highest rating down, keeping the given order among equals.
+// The tournament seeds its heats in this order.
+func Rank(ratings []float64) []int {
+	order := make([]int, len(ratings))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case ratings[a] > ratings[b]:
+			return -1
+		case ratings[a] < ratings[b]:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
@@ -0,0 +1,309 @@
+package tournament
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"snakehem/model"
+	"snakehem/rating"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Tournament is a knockout of heats: the best players of each heat go on to the next round, until
+// a round of a single heat decides the champion. Progress is saved after every heat.
+type Tournament struct {
+	HeatSize int `json:"heatSize"`
+	// Advance is how many players go on from each heat. At least one player is knocked out of every heat.
+	Advance  int      `json:"advance"`
+	Rounds   []*Round `json:"rounds"`
+	Champion string   `json:"champion,omitempty"`
+	path     string
+}
+
+type Round struct {
+	Heats []*Heat `json:"heats"`
+}
+
+type Heat struct {
+	// Players are in the order of their seeds, which settles ties
+	Players []string `json:"players"`
+	// Scores are indexed the same way as Players, nil until the heat is played
+	Scores []int `json:"scores,omitempty"`
+}
+
+// Forfeit is the score of a player who didn't show up for a heat, below any score of a player who did.
+const Forfeit = -1
+
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "snakehem", "tournament.json")
+}
+
+// ReadRoster reads the names of the players, one per line. Blank lines and lines starting with # are skipped.
+func ReadRoster(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		if slices.ContainsFunc(names, func(other string) bool { return strings.EqualFold(other, name) }) {
+			return nil, fmt.Errorf("player %q is on the roster twice", name)
+		}
+		names = append(names, name)
+	}
+	return names, scanner.Err()
+}
+
+// Load resumes the tournament saved at path. It returns nil and no error if there's none.
+func Load(path string) (*Tournament, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := &Tournament{path: path}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("tournament %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("tournament %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// validate checks what the rest of the tournament relies on, so that a hand-edited file fails
+// when it's loaded rather than in the middle of a heat.
+func (t *Tournament) validate() error {
+	if t.HeatSize < 2 || t.HeatSize > model.MaxSnakes {
+		return fmt.Errorf("heats need from 2 to %d players, not %d", model.MaxSnakes, t.HeatSize)
+	}
+	if t.Advance < 1 {
+		return fmt.Errorf("at least 1 player has to advance from each heat, not %d", t.Advance)
+	}
+	if len(t.Rounds) == 0 && !t.IsOver() {
+		return errors.New("no rounds and no champion")
+	}
+	for r, round := range t.Rounds {
+		if round == nil || len(round.Heats) == 0 {
+			return fmt.Errorf("round %d has no heats", r+1)
+		}
+		for i, h := range round.Heats {
+			if h == nil || len(h.Players) == 0 || len(h.Players) > model.MaxSnakes {
+				return fmt.Errorf("heat %d of round %d needs from 1 to %d players", i+1, r+1, model.MaxSnakes)
+			}
+			if h.Scores != nil && len(h.Scores) != len(h.Players) {
+				return fmt.Errorf("heat %d of round %d has %d scores for %d players", i+1, r+1, len(h.Scores), len(h.Players))
+			}
+		}
+	}
+	if h, _ := t.CurrentHeat(); h == nil && !t.IsOver() {
+		return errors.New("every heat is played but there's no champion")
+	}
+	return nil
+}
+
+// New draws the first round from the roster, seeded by the skill ratings of the players, and saves it to path.
+func New(roster []string, ratings []float64, heatSize, advance int, path string) (*Tournament, error) {
+	if len(roster) < 2 {
+		return nil, fmt.Errorf("a tournament needs at least 2 players, the roster has %d", len(roster))
+	}
+	if heatSize < 2 || heatSize > model.MaxSnakes {
+		return nil, fmt.Errorf("heats need from 2 to %d players, not %d", model.MaxSnakes, heatSize)
+	}
+	if advance < 1 {
+		return nil, fmt.Errorf("at least 1 player has to advance from each heat, not %d", advance)
+	}
+	seeded := make([]string, len(roster))
+	for i, idx := range rating.Rank(ratings) {
+		seeded[i] = roster[idx]
+	}
+	t := &Tournament{
+		HeatSize: heatSize,
+		Advance:  advance,
+		Rounds:   nil,
+		Champion: "",
+		path:     path,
+	}
+	t.draw(seeded)
+	t.Save()
+	return t, nil
+}
+
+// draw splits the players, best seeds first, into as few heats as fit them. The seeds are dealt
+// back and forth, so that every heat gets a fair share of strong and weak players.
+func (t *Tournament) draw(seeded []string) {
+	heatCount := (len(seeded) + t.HeatSize - 1) / t.HeatSize
+	heats := make([]*Heat, heatCount)
+	for i := range heats {
+		heats[i] = &Heat{Players: nil, Scores: nil}
+	}
+	for i, name := range seeded {
+		idx := i % heatCount
+		if (i/heatCount)%2 == 1 {
+			idx = heatCount - 1 - idx
+		}
+		heats[idx].Players = append(heats[idx].Players, name)
+	}
+	for _, h := range heats {
+		if len(h.Players) == 1 {
+			// a bye, which only happens to the best seed of an odd number of players in duels
+			h.Scores = []int{0}
+		}
+	}
+	t.Rounds = append(t.Rounds, &Round{Heats: heats})
+}
+
+// Save writes the tournament to a temporary file first, so that a crash while saving doesn't
+// leave a broken file behind.
+func (t *Tournament) Save() {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(t.path), 0o755)
+	}
+	tmp := t.path + ".tmp"
+	if err == nil {
+		err = os.WriteFile(tmp, data, 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp, t.path)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("path", t.path).Msg("Failed to save the tournament")
+	}
+}
+
+func (t *Tournament) IsOver() bool {
+	return t.Champion != ""
+}
+
+// CurrentHeat is the first heat of the last round which hasn't been played yet, nil when the tournament is over.
+func (t *Tournament) CurrentHeat() (*Heat, int) {
+	if t.IsOver() {
+		return nil, -1
+	}
+	for i, h := range t.Rounds[len(t.Rounds)-1].Heats {
+		if !h.IsPlayed() {
+			return h, i
+		}
+	}
+	return nil, -1
+}
+
+func (h *Heat) IsPlayed() bool {
+	return h.Scores != nil
+}
+
+// Has tells if a player is in the heat, ignoring case like the player profiles do.
+func (h *Heat) Has(name string) bool {
+	return slices.ContainsFunc(h.Players, func(p string) bool { return strings.EqualFold(p, name) })
+}
+
+// Standings are the players of a played heat from the winner down. Equal scores go to the better seed.
+func (h *Heat) Standings() []string {
+	order := h.order()
+	result := make([]string, len(order))
+	for i, idx := range order {
+		result[i] = h.Players[idx]
+	}
+	return result
+}
+
+// order lists the indices of the players from the winner down.
+func (h *Heat) order() []int {
+	order := make([]int, len(h.Players))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return h.Scores[b] - h.Scores[a]
+	})
+	return order
+}
+
+// Advancing are the players who go on from a played heat. Someone is always knocked out, unless the heat
+// is a bye, and nobody goes on by forfeit.
+func (t *Tournament) Advancing(h *Heat) []string {
+	var advancing []string
+	for _, idx := range h.order()[:min(t.Advance, max(1, len(h.Players)-1))] {
+		if h.Scores[idx] != Forfeit {
+			advancing = append(advancing, h.Players[idx])
+		}
+	}
+	return advancing
+}
+
+// RecordHeat stores the scores of the current heat by player name, moving on to the next round once
+// the last heat of a round is played, and saves the tournament. The players missing from the scores forfeit.
+func (t *Tournament) RecordHeat(scores map[string]int) {
+	h, idx := t.CurrentHeat()
+	if h == nil {
+		return
+	}
+	h.Scores = make([]int, len(h.Players))
+	for i, name := range h.Players {
+		h.Scores[i] = Forfeit
+		for n, score := range scores {
+			if strings.EqualFold(n, name) {
+				h.Scores[i] = score
+			}
+		}
+	}
+	log.Info().Int("round", len(t.Rounds)).Int("heat", idx+1).Strs("standings", h.Standings()).Msg("Heat played")
+	round := t.Rounds[len(t.Rounds)-1]
+	if !slices.ContainsFunc(round.Heats, func(h *Heat) bool { return !h.IsPlayed() }) {
+		t.finishRound(round)
+	}
+	t.Save()
+}
+
+// finishRound draws the next round from the players who advance, the heat winners being seeded first,
+// then the runners-up and so on. The winner of a round of a single heat is the champion, and so is
+// the only player left once the others have forfeited.
+func (t *Tournament) finishRound(round *Round) {
+	if len(round.Heats) == 1 {
+		t.crown(round.Heats[0].Standings()[0])
+		return
+	}
+	var seeded []string
+	for place := 0; ; place++ {
+		added := false
+		for _, h := range round.Heats {
+			if advancing := t.Advancing(h); place < len(advancing) {
+				seeded = append(seeded, advancing[place])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	if len(seeded) == 1 {
+		t.crown(seeded[0])
+		return
+	}
+	t.draw(seeded)
+}
+
+func (t *Tournament) crown(champion string) {
+	t.Champion = champion
+	log.Info().Str("champion", t.Champion).Msg("Tournament won")
+}